@@ -0,0 +1,124 @@
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/jsonschema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type FromJSONSchemaSettings struct {
+	SchemaFile string `glazed.parameter:"schema-file"`
+	PrintForm  bool   `glazed.parameter:"print-form"`
+	Answers    string `glazed.parameter:"answers"`
+	Output     string `glazed.parameter:"output"`
+}
+
+// FromJSONSchemaCommand runs a form generated from a JSON Schema and prints
+// the document it fills in.
+type FromJSONSchemaCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &FromJSONSchemaCommand{}
+
+func NewFromJSONSchemaCommand() (*FromJSONSchemaCommand, error) {
+	return &FromJSONSchemaCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"from-jsonschema",
+			cmds.WithShort("Fill in a JSON document with a form generated from its JSON Schema"),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"schema-file",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Path to the JSON Schema of an object"),
+					parameters.WithRequired(true),
+				),
+			),
+			cmds.WithFlags(
+				parameters.NewParameterDefinition(
+					"print-form",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Print the generated form as YAML instead of running it"),
+					parameters.WithDefault(false),
+				),
+				parameters.NewParameterDefinition(
+					"answers",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Run without a terminal, answering the form from this YAML/JSON file (field key -> answer)"),
+				),
+				parameters.NewParameterDefinition(
+					"output",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Write the document to this file instead of stdout"),
+				),
+			),
+		),
+	}, nil
+}
+
+func (c *FromJSONSchemaCommand) Run(
+	ctx context.Context,
+	parsedLayers *layers.ParsedLayers,
+) error {
+	s := &FromJSONSchemaSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "failed to initialize settings")
+	}
+
+	schema, err := jsonschema.LoadSchema(s.SchemaFile)
+	if err != nil {
+		return err
+	}
+	sf, err := jsonschema.NewSchemaForm(schema)
+	if err != nil {
+		return errors.Wrapf(err, "could not generate a form from %s", s.SchemaFile)
+	}
+
+	if s.PrintForm {
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(sf.Form); err != nil {
+			return errors.Wrap(err, "could not write form")
+		}
+		return encoder.Close()
+	}
+
+	if s.Answers != "" {
+		answers, err := pkg.LoadAnswers(s.Answers)
+		if err != nil {
+			return err
+		}
+		ctx = pkg.WithAnswers(ctx, answers)
+	}
+
+	values, err := sf.Form.Run(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error running form")
+	}
+	doc, err := sf.Document(values)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not encode document")
+	}
+	data = append(data, '\n')
+	if s.Output != "" {
+		if err := os.WriteFile(s.Output, data, 0644); err != nil {
+			return errors.Wrapf(err, "could not write %s", s.Output)
+		}
+		return nil
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
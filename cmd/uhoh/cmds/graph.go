@@ -0,0 +1,82 @@
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/uhoh/pkg/wizard"
+	"github.com/pkg/errors"
+)
+
+type GraphSettings struct {
+	WizardFile string `glazed.parameter:"wizard-file"`
+	Format     string `glazed.parameter:"format"`
+}
+
+// GraphCommand prints the step graph of a wizard.
+type GraphCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &GraphCommand{}
+
+func NewGraphCommand() (*GraphCommand, error) {
+	return &GraphCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"graph",
+			cmds.WithShort("Print the flow of a wizard as a Mermaid, Graphviz (DOT) or JSON graph"),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"wizard-file",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Path to the wizard YAML file"),
+					parameters.WithRequired(true),
+				),
+			),
+			cmds.WithFlags(
+				parameters.NewParameterDefinition(
+					"format",
+					parameters.ParameterTypeChoice,
+					parameters.WithHelp("Output format"),
+					parameters.WithChoices("mermaid", "dot", "json"),
+					parameters.WithDefault("mermaid"),
+				),
+			),
+		),
+	}, nil
+}
+
+func (c *GraphCommand) Run(
+	ctx context.Context,
+	parsedLayers *layers.ParsedLayers,
+) error {
+	s := &GraphSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "failed to initialize settings")
+	}
+
+	wz, err := wizard.LoadWizard(s.WizardFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to load wizard")
+	}
+	graph := wz.Graph()
+
+	switch s.Format {
+	case "dot":
+		fmt.Print(graph.DOT())
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(graph); err != nil {
+			return errors.Wrap(err, "could not write graph")
+		}
+	default:
+		fmt.Print(graph.Mermaid())
+	}
+	return nil
+}
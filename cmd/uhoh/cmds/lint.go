@@ -0,0 +1,106 @@
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/uhoh/pkg/lint"
+	"github.com/pkg/errors"
+)
+
+type LintSettings struct {
+	Files     []string `glazed.parameter:"files"`
+	Output    string   `glazed.parameter:"output"`
+	Callbacks []string `glazed.parameter:"callback"`
+	StateKeys []string `glazed.parameter:"state-key"`
+}
+
+// LintCommand checks wizard, command and form files without running them.
+type LintCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &LintCommand{}
+
+func NewLintCommand() (*LintCommand, error) {
+	return &LintCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"lint",
+			cmds.WithShort("Check wizard, command and form files for mistakes without running them"),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"files",
+					parameters.ParameterTypeStringList,
+					parameters.WithHelp("Wizard, command or form files (YAML) to check"),
+					parameters.WithRequired(true),
+				),
+			),
+			cmds.WithFlags(
+				parameters.NewParameterDefinition(
+					"output",
+					parameters.ParameterTypeChoice,
+					parameters.WithHelp("Output format"),
+					parameters.WithChoices("text", "json"),
+					parameters.WithDefault("text"),
+				),
+				parameters.NewParameterDefinition(
+					"callback",
+					parameters.ParameterTypeStringList,
+					parameters.WithHelp("Callbacks and action functions registered by the program running the wizard"),
+					parameters.WithDefault([]string{}),
+				),
+				parameters.NewParameterDefinition(
+					"state-key",
+					parameters.ParameterTypeStringList,
+					parameters.WithHelp("Keys passed in the initial state of the wizard"),
+					parameters.WithDefault([]string{}),
+				),
+			),
+		),
+	}, nil
+}
+
+func (c *LintCommand) Run(
+	ctx context.Context,
+	parsedLayers *layers.ParsedLayers,
+) error {
+	s := &LintSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "failed to initialize settings")
+	}
+
+	opts := lint.Options{
+		Callbacks: s.Callbacks,
+		StateKeys: s.StateKeys,
+	}
+	issues := []lint.Issue{}
+	for _, path := range s.Files {
+		fileIssues, err := lint.LintFile(path, opts)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	if s.Output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(issues); err != nil {
+			return errors.Wrap(err, "could not write issues")
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+	}
+
+	if lint.HasErrors(issues) {
+		return errors.Errorf("%d issue(s) found, including errors", len(issues))
+	}
+	return nil
+}
@@ -2,12 +2,14 @@ package cmds
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/go-go-golems/glazed/pkg/cli"
 	glazed_cmds "github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/alias"
 	"github.com/go-go-golems/glazed/pkg/cmds/loaders"
 	"github.com/go-go-golems/glazed/pkg/help"
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/go-go-golems/uhoh/pkg/cmds"
 	"github.com/go-go-golems/uhoh/pkg/doc"
 	"github.com/pkg/errors"
@@ -18,23 +20,29 @@ import (
 // This command doesn't fit the glazed.Command pattern well because its primary function
 // is to load and execute *another* command.
 func NewRunCommandCobraCmd() *cobra.Command {
+	var answersFile string
 	cmd := &cobra.Command{
 		Use:   "run-command [command-file] [args...]",
 		Short: "Run a command defined in a YAML file",
 		Args:  cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			// The first argument is the command file, the rest are passed down
-			err := handleRunCommand(args[0], args[1:])
+			err := handleRunCommand(args[0], args[1:], answersFile)
 			cobra.CheckErr(err)
 		},
 	}
+	cmd.Flags().StringVar(&answersFile, "answers", "",
+		"Run without a terminal, answering the form from this YAML/JSON file (field key -> answer)")
+	// Flags after the command file belong to the loaded command
+	cmd.Flags().SetInterspersed(false)
 	return cmd
 }
 
 // handleRunCommand loads and executes a command defined in a file.
 // This function remains largely the same as its previous version in main.go.
-func handleRunCommand(commandFile string, commandArgs []string) error {
-	loader := &cmds.UhohCommandLoader{}
+// If answersFile is set, the form is answered from it instead of being shown.
+func handleRunCommand(commandFile string, commandArgs []string, answersFile string) error {
+	loader := &cmds.UhohCommandLoader{Dir: filepath.Dir(commandFile)}
 
 	// Use the provided commandFile argument
 	fs_, filePath, err := loaders.FileNameToFsFilePath(commandFile)
@@ -55,6 +63,18 @@ func handleRunCommand(commandFile string, commandArgs []string) error {
 
 	loadedCmd := cmds_[0]
 
+	if answersFile != "" {
+		uhohCmd, ok := loadedCmd.(*cmds.UhohCommand)
+		if !ok {
+			return errors.Errorf("--answers is only supported for uhoh form commands, %s is a %T", commandFile, loadedCmd)
+		}
+		answers, err := pkg.LoadAnswers(answersFile)
+		if err != nil {
+			return err
+		}
+		uhohCmd.SetAnswers(answers)
+	}
+
 	// We need a temporary root command to execute the loaded command
 	// because the original rootCmd might have already parsed its flags.
 	tempRootCmd := &cobra.Command{
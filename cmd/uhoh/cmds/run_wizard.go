@@ -8,6 +8,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/go-go-golems/uhoh/pkg/wizard"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -18,6 +19,9 @@ type RunWizardSettings struct {
 	WizardFile       string                 `glazed.parameter:"wizard-file"`
 	InitialState     map[string]string      `glazed.parameter:"initial-state"`
 	InitialStateFile map[string]interface{} `glazed.parameter:"initial-state-file"`
+	Checkpoint       string                 `glazed.parameter:"checkpoint"`
+	Resume           string                 `glazed.parameter:"resume"`
+	Answers          string                 `glazed.parameter:"answers"`
 }
 
 type RunWizardCommand struct {
@@ -50,6 +54,21 @@ func NewRunWizardCommand() (*RunWizardCommand, error) {
 					parameters.ParameterTypeObjectFromFile,
 					parameters.WithHelp("File containing initial state for the wizard (JSON/YAML)"),
 				),
+				parameters.NewParameterDefinition(
+					"checkpoint",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Save progress to this JSON file after every step (removed when the wizard finishes)"),
+				),
+				parameters.NewParameterDefinition(
+					"resume",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Resume from a checkpoint file; progress keeps being saved to it unless --checkpoint is given"),
+				),
+				parameters.NewParameterDefinition(
+					"answers",
+					parameters.ParameterTypeString,
+					parameters.WithHelp("Run without a terminal, answering the steps from this YAML/JSON file (step ID -> field key -> answer)"),
+				),
 			),
 		),
 	}, nil
@@ -82,9 +101,34 @@ func (c *RunWizardCommand) Run(
 		}
 	}
 
+	opts := []wizard.WizardOption{wizard.WithInitialState(initialState)}
+
+	checkpointFile := s.Checkpoint
+	if s.Resume != "" {
+		checkpoint, err := wizard.LoadCheckpoint(s.Resume)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, wizard.WithResume(checkpoint))
+		if checkpointFile == "" {
+			checkpointFile = s.Resume
+		}
+	}
+	if checkpointFile != "" {
+		opts = append(opts, wizard.WithCheckpointFile(checkpointFile))
+	}
+
+	if s.Answers != "" {
+		answers, err := pkg.LoadAnswers(s.Answers)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, wizard.WithScriptedAnswers(answers))
+	}
+
 	// Load the wizard using LoadWizard, applying the prepared initial state
 	// NOTE: LoadWizard itself applies options; we pass the state here to Run.
-	wz, err := wizard.LoadWizard(s.WizardFile, wizard.WithInitialState(initialState))
+	wz, err := wizard.LoadWizard(s.WizardFile, opts...)
 	if err != nil {
 		return errors.Wrapf(err, "error loading wizard from file: %s", s.WizardFile)
 	}
@@ -0,0 +1,65 @@
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/uhoh/pkg/jsonschema"
+	"github.com/pkg/errors"
+)
+
+type SchemaSettings struct {
+	Kind string `glazed.parameter:"kind"`
+}
+
+// SchemaCommand prints the JSON Schema of the form, command or wizard YAML
+// format.
+type SchemaCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &SchemaCommand{}
+
+func NewSchemaCommand() (*SchemaCommand, error) {
+	return &SchemaCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"schema",
+			cmds.WithShort("Print the JSON Schema of form, command or wizard files, for editor completion and validation"),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"kind",
+					parameters.ParameterTypeChoice,
+					parameters.WithHelp("Kind of file"),
+					parameters.WithChoices(jsonschema.KindForm, jsonschema.KindCommand, jsonschema.KindWizard),
+					parameters.WithDefault(jsonschema.KindWizard),
+				),
+			),
+		),
+	}, nil
+}
+
+func (c *SchemaCommand) Run(
+	ctx context.Context,
+	parsedLayers *layers.ParsedLayers,
+) error {
+	s := &SchemaSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "failed to initialize settings")
+	}
+
+	schema, err := jsonschema.Generate(s.Kind)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return errors.Wrap(err, "could not write schema")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/uhoh/pkg/wizard/scenario"
+	"github.com/pkg/errors"
+)
+
+type TestWizardSettings struct {
+	ScenarioFiles []string `glazed.parameter:"scenario-files"`
+	Update        bool     `glazed.parameter:"update"`
+}
+
+// TestWizardCommand runs wizards headless against scenario files.
+type TestWizardCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &TestWizardCommand{}
+
+func NewTestWizardCommand() (*TestWizardCommand, error) {
+	return &TestWizardCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"test",
+			cmds.WithShort("Run wizard scenarios and check their path, final state, errors and views"),
+			cmds.WithArguments(
+				parameters.NewParameterDefinition(
+					"scenario-files",
+					parameters.ParameterTypeStringList,
+					parameters.WithHelp("Scenario files (YAML) to run"),
+					parameters.WithRequired(true),
+				),
+			),
+			cmds.WithFlags(
+				parameters.NewParameterDefinition(
+					"update",
+					parameters.ParameterTypeBool,
+					parameters.WithHelp("Rewrite the golden files with the views of this run"),
+					parameters.WithDefault(false),
+				),
+			),
+		),
+	}, nil
+}
+
+func (c *TestWizardCommand) Run(
+	ctx context.Context,
+	parsedLayers *layers.ParsedLayers,
+) error {
+	s := &TestWizardSettings{}
+	if err := parsedLayers.InitializeStruct(layers.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "failed to initialize settings")
+	}
+
+	total, failed := 0, 0
+	for _, path := range s.ScenarioFiles {
+		file, err := scenario.Load(path)
+		if err != nil {
+			return err
+		}
+		results, err := file.Run(ctx, s.Update)
+		if err != nil {
+			return errors.Wrapf(err, "could not run %s", path)
+		}
+
+		for _, res := range results {
+			total++
+			if res.Passed() {
+				fmt.Printf("PASS %s: %s\n", path, res.Scenario.Name)
+				continue
+			}
+			failed++
+			fmt.Printf("FAIL %s: %s\n", path, res.Scenario.Name)
+			for _, failure := range res.Failures {
+				fmt.Printf("    %s\n", strings.ReplaceAll(failure, "\n", "\n    "))
+			}
+		}
+	}
+
+	fmt.Printf("\n%d scenario(s), %d failed\n", total, failed)
+	if failed > 0 {
+		return errors.Errorf("%d of %d scenario(s) failed", failed, total)
+	}
+	return nil
+}
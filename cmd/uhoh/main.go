@@ -94,6 +94,36 @@ func main() {
 	cobra.CheckErr(err)
 	rootCmd.AddCommand(cobraRunWizardCmd)
 
+	testWizardCmd, err := app_cmds.NewTestWizardCommand()
+	cobra.CheckErr(err)
+	cobraTestWizardCmd, err := cli.BuildCobraCommandFromBareCommand(testWizardCmd)
+	cobra.CheckErr(err)
+	rootCmd.AddCommand(cobraTestWizardCmd)
+
+	lintCmd, err := app_cmds.NewLintCommand()
+	cobra.CheckErr(err)
+	cobraLintCmd, err := cli.BuildCobraCommandFromBareCommand(lintCmd)
+	cobra.CheckErr(err)
+	rootCmd.AddCommand(cobraLintCmd)
+
+	graphCmd, err := app_cmds.NewGraphCommand()
+	cobra.CheckErr(err)
+	cobraGraphCmd, err := cli.BuildCobraCommandFromBareCommand(graphCmd)
+	cobra.CheckErr(err)
+	rootCmd.AddCommand(cobraGraphCmd)
+
+	schemaCmd, err := app_cmds.NewSchemaCommand()
+	cobra.CheckErr(err)
+	cobraSchemaCmd, err := cli.BuildCobraCommandFromBareCommand(schemaCmd)
+	cobra.CheckErr(err)
+	rootCmd.AddCommand(cobraSchemaCmd)
+
+	fromJSONSchemaCmd, err := app_cmds.NewFromJSONSchemaCommand()
+	cobra.CheckErr(err)
+	cobraFromJSONSchemaCmd, err := cli.BuildCobraCommandFromBareCommand(fromJSONSchemaCmd)
+	cobra.CheckErr(err)
+	rootCmd.AddCommand(cobraFromJSONSchemaCmd)
+
 	// Add the dynamic run-command from the cmds package
 	runCmdCobra := app_cmds.NewRunCommandCobraCmd()
 	rootCmd.AddCommand(runCmdCobra)
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-go-golems/uhoh/pkg/wizard"
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+//go:embed wizard.yaml
+var wizardFS embed.FS
+
+// dashboardModel hosts an onboarding wizard in its right pane.
+type dashboardModel struct {
+	wizard tea.Model
+	status string
+	result map[string]interface{}
+	width  int
+	height int
+}
+
+var paneStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+
+func newDashboardModel() (dashboardModel, error) {
+	wz, err := loadWizard()
+	if err != nil {
+		return dashboardModel{}, err
+	}
+	return dashboardModel{
+		wizard: wz.BuildBubbleTeaModel(map[string]interface{}{}),
+		status: "onboarding…",
+	}, nil
+}
+
+// loadWizard writes the embedded wizard to a temporary file, as
+// wizard.LoadWizard reads from disk.
+func loadWizard() (*wizard.Wizard, error) {
+	b, err := wizardFS.ReadFile("wizard.yaml")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "uhoh-embed")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	path := filepath.Join(dir, "wizard.yaml")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return nil, err
+	}
+	return wizard.LoadWizard(path)
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return m.wizard.Init()
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch t := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = t.Width, t.Height
+		// The wizard pane gets two thirds of the width, minus the borders
+		var cmd tea.Cmd
+		m.wizard, cmd = m.wizard.Update(tea.WindowSizeMsg{Width: t.Width*2/3 - 4, Height: t.Height - 2})
+		return m, cmd
+
+	case tea.KeyMsg:
+		if m.wizard == nil && (t.String() == "q" || t.String() == "ctrl+c") {
+			return m, tea.Quit
+		}
+
+	case wizard.CompletedMsg:
+		m.status = "onboarding complete"
+		m.result = t.State
+		m.wizard = nil
+		return m, nil
+
+	case wizard.AbortedMsg:
+		if errors.Is(t.Err, steps.ErrUserAborted) {
+			m.status = "onboarding aborted"
+		} else {
+			m.status = fmt.Sprintf("onboarding failed: %v", t.Err)
+		}
+		m.wizard = nil
+		return m, nil
+	}
+
+	// Every other message goes to the wizard while it runs
+	if m.wizard != nil {
+		var cmd tea.Cmd
+		m.wizard, cmd = m.wizard.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m dashboardModel) View() string {
+	left := fmt.Sprintf("Dashboard\n\nStatus: %s\n", m.status)
+	if m.result != nil {
+		left += fmt.Sprintf("\nName: %v\nTeam: %v\n", m.result["name"], m.result["team"])
+	}
+	if m.wizard == nil {
+		left += "\n(q to quit)"
+	}
+
+	right := "No wizard running."
+	if m.wizard != nil {
+		right = m.wizard.View()
+	}
+
+	leftWidth := m.width/3 - 4
+	if leftWidth < 20 {
+		leftWidth = 20
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		paneStyle.Width(leftWidth).Render(left),
+		paneStyle.Render(right),
+	)
+}
+
+func main() {
+	m, err := newDashboardModel()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,258 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadAnswers reads a YAML or JSON file of scripted answers. For a form, the
+// file maps field keys to answers; for a wizard, it maps step IDs to the
+// answers of that step.
+func LoadAnswers(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read answers file %s", path)
+	}
+	answers := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, errors.Wrapf(err, "could not parse answers file %s", path)
+	}
+	return answers, nil
+}
+
+type answersKey struct{}
+
+// WithAnswers returns a headless context: Form.Run answers the form from
+// answers (keyed by field key) instead of showing it, RunHuhForm accepts
+// forms without showing them, and the wizard steps read their input from
+// answers too.
+func WithAnswers(ctx context.Context, answers map[string]interface{}) context.Context {
+	if answers == nil {
+		answers = map[string]interface{}{}
+	}
+	return context.WithValue(ctx, answersKey{}, answers)
+}
+
+// AnswersFrom returns the answers stored in ctx by WithAnswers. ok is false
+// if the context is not headless.
+func AnswersFrom(ctx context.Context) (answers map[string]interface{}, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	answers, ok = ctx.Value(answersKey{}).(map[string]interface{})
+	return answers, ok
+}
+
+// IsHeadless reports whether ctx was created by WithAnswers.
+func IsHeadless(ctx context.Context) bool {
+	_, ok := AnswersFrom(ctx)
+	return ok
+}
+
+// ViewRecorder receives the views a headless run would have shown, such as a
+// form with its answers filled in, for snapshots.
+type ViewRecorder func(view string)
+
+type viewRecorderKey struct{}
+
+// viewWidth is the width forms are rendered with for a ViewRecorder.
+const viewWidth = 80
+
+// WithViewRecorder returns a context in which headless forms are rendered
+// and passed to record, without ANSI styling.
+func WithViewRecorder(ctx context.Context, record ViewRecorder) context.Context {
+	return context.WithValue(ctx, viewRecorderKey{}, record)
+}
+
+// recordView renders form for the ViewRecorder in ctx, if any.
+func recordView(ctx context.Context, form *huh.Form) {
+	record, ok := ctx.Value(viewRecorderKey{}).(ViewRecorder)
+	if !ok || record == nil {
+		return
+	}
+	form = form.WithWidth(viewWidth)
+	form.Init()
+	record(ansi.Strip(form.View()))
+}
+
+// Answer fills in the form from answers, keyed by field key, without a
+// terminal, and returns the same values Run would. Answers go through the
+// same conversions as pre-filled values and the same checks as typed input:
+// select answers must be one of the option values, typed fields must parse,
+// and required fields and validations are enforced. Fields hidden by a
+// visible_condition are skipped, as are their answers.
+//
+// A field without an answer keeps its default, as if the user submitted it
+// untouched. A required field without an answer or default, a select without
+// an answer or default, and a default that does not pass validation are
+// reported as missing answers, with the form name and field key.
+func (f *Form) Answer(answers map[string]interface{}) (map[string]interface{}, error) {
+	_, values, err := f.answer(answers)
+	if err != nil && f.Name != "" {
+		return nil, errors.Wrapf(err, "form %s", f.Name)
+	}
+	return values, err
+}
+
+// answer fills in the form like Answer, and also returns the huh form built
+// from the answers.
+func (f *Form) answer(answers map[string]interface{}) (*huh.Form, map[string]interface{}, error) {
+	ret := f.clone()
+
+	known := map[string]bool{}
+	for _, group := range ret.Groups {
+		for _, field := range group.Fields {
+			if field.Type == "note" || field.Key == "" {
+				continue
+			}
+			known[field.Key] = true
+
+			answer, ok := answers[field.Key]
+			if !ok {
+				continue
+			}
+			value, err := answerValue(field, answer)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "invalid answer for field %s", field.Key)
+			}
+			field.Value = value
+		}
+	}
+
+	var unknown []string
+	for key := range answers {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, nil, errors.Errorf("answers given for unknown fields: %s", strings.Join(unknown, ", "))
+	}
+
+	huhForm, values, err := ret.BuildBubbleTeaModel()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, group := range ret.Groups {
+		for _, field := range group.Fields {
+			if field.Type == "note" || field.Key == "" {
+				continue
+			}
+			ptr := values[field.Key]
+			if cv, ok := ptr.(*conditionalValue); ok {
+				if !cv.visible() {
+					continue
+				}
+				ptr = cv.ptr
+			}
+			_, answered := answers[field.Key]
+
+			// huh selects the first option of a select bound to no option,
+			// so look at the answer or default instead of the bound index
+			if sv, ok := ptr.(*selectValue); ok && len(sv.options) > 0 && optionIndex(sv.options, field.Value) < 0 {
+				return nil, nil, errors.Errorf("missing answer for field %s: expected one of %s",
+					field.Key, optionList(sv.options))
+			}
+			if !answered && field.Required && isBlank(boundValue(ptr)) {
+				return nil, nil, errors.Errorf("missing answer for required field %s", field.Key)
+			}
+
+			if !field.Required && len(field.Validation) == 0 && !isTypedField(field.Type) {
+				continue
+			}
+			validator, err := newFieldValidator(field, values)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := validator.validate(boundValue(ptr)); err != nil {
+				if !answered {
+					return nil, nil, errors.Wrapf(err, "missing answer for field %s", field.Key)
+				}
+				return nil, nil, errors.Wrapf(err, "invalid answer for field %s", field.Key)
+			}
+		}
+	}
+
+	finalValues, err := ExtractFinalValues(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	return huhForm, finalValues, nil
+}
+
+// answerValue converts an answer into the value the field starts with.
+// Select and multiselect answers must match an option value.
+func answerValue(field *Field, answer interface{}) (interface{}, error) {
+	value, err := coerceFieldValue(field.Type, answer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch field.Type {
+	case "select":
+		if optionIndex(field.Options, value) < 0 {
+			return nil, errors.Errorf("%v is not one of %s", value, optionList(field.Options))
+		}
+	case "multiselect":
+		for _, v := range value.([]interface{}) {
+			if optionIndex(field.Options, v) < 0 {
+				return nil, errors.Errorf("%v is not one of %s", v, optionList(field.Options))
+			}
+		}
+	}
+	return value, nil
+}
+
+// boundValue returns the value of a bound field the way the huh field passes
+// it to its validation: the text of typed fields, option values of selects.
+func boundValue(ptr interface{}) interface{} {
+	switch p := ptr.(type) {
+	case *string:
+		return *p
+	case *bool:
+		return *p
+	case *selectValue:
+		return p.value()
+	case *multiSelectValue:
+		return p.value()
+	case *typedValue:
+		return *p.text
+	default:
+		return nil
+	}
+}
+
+// isBlank reports whether a bound value is empty, as a required field
+// without a default starts.
+func isBlank(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(t) == ""
+	case []interface{}:
+		return len(t) == 0
+	case []string:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
+
+func optionList(options []*Option) string {
+	values := make([]string, 0, len(options))
+	for _, opt := range options {
+		values = append(values, fmt.Sprintf("%v", opt.Value))
+	}
+	return strings.Join(values, ", ")
+}
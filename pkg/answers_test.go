@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormAnswer(t *testing.T) {
+	form := func() *Form {
+		return &Form{Name: "signup", Groups: []*Group{
+			{Fields: []*Field{
+				{Type: "input", Key: "name", Required: true},
+				{Type: "input", Key: "nickname", Value: "none"},
+				{Type: "select", Key: "plan", Options: []*Option{{Label: "Free", Value: "free"}, {Label: "Pro", Value: "pro"}}},
+				{Type: "multiselect", Key: "tags", Options: []*Option{{Label: "A", Value: "a"}, {Label: "B", Value: "b"}}},
+				{Type: "integer", Key: "seats", Value: 1, Validation: []*Validation{{Condition: `value == 13`, Error: "unlucky"}}},
+				{Type: "confirm", Key: "subscribe"},
+			}},
+			{VisibleCondition: `plan == "pro"`, Fields: []*Field{
+				{Type: "input", Key: "company", Required: true},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		answers  map[string]interface{}
+		expected map[string]interface{}
+		problem  string
+	}{
+		{
+			name:    "defaults",
+			answers: map[string]interface{}{"name": "Ada", "plan": "free"},
+			expected: map[string]interface{}{
+				"name": "Ada", "nickname": "none", "plan": "free", "tags": []string{}, "seats": int64(1), "subscribe": false,
+			},
+		},
+		{
+			name:    "all answers",
+			answers: map[string]interface{}{"name": "Ada", "plan": "pro", "company": "ACME", "tags": "a, b", "seats": "3", "subscribe": "yes"},
+			expected: map[string]interface{}{
+				"name": "Ada", "nickname": "none", "plan": "pro", "company": "ACME", "tags": []string{"a", "b"}, "seats": int64(3), "subscribe": true,
+			},
+		},
+		{"missing required answer", map[string]interface{}{"plan": "free"}, nil, "form signup: missing answer for required field name"},
+		{"missing select answer", map[string]interface{}{"name": "Ada"}, nil, "missing answer for field plan: expected one of free, pro"},
+		{"unknown option", map[string]interface{}{"name": "Ada", "plan": "gold"}, nil, "invalid answer for field plan: gold is not one of free, pro"},
+		{"unknown multiselect option", map[string]interface{}{"name": "Ada", "plan": "free", "tags": []string{"c"}}, nil, "c is not one of a, b"},
+		{"unknown field", map[string]interface{}{"name": "Ada", "plan": "free", "age": 3}, nil, "answers given for unknown fields: age"},
+		{"typed answer", map[string]interface{}{"name": "Ada", "plan": "free", "seats": "many"}, nil, "invalid answer for field seats"},
+		{"validation", map[string]interface{}{"name": "Ada", "plan": "free", "seats": 13}, nil, "unlucky"},
+		{"bad confirm", map[string]interface{}{"name": "Ada", "plan": "free", "subscribe": "perhaps"}, nil, "invalid answer for field subscribe"},
+		{"visible group", map[string]interface{}{"name": "Ada", "plan": "pro"}, nil, "missing answer for required field company"},
+	}
+	for _, tt := range tests {
+		got, err := form().Answer(tt.answers)
+		if tt.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Validate checks what can only go wrong once the form is shown: that the
+// visible and validation conditions compile and that the titles,
+// descriptions, defaults, option labels and placeholders are valid
+// templates. Loaders call it so broken forms fail when they are loaded.
+func (f *Form) Validate() error {
+	for i, group := range f.Groups {
+		if err := group.Validate(); err != nil {
+			name := group.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", i+1)
+			}
+			return errors.Wrapf(err, "group %s", name)
+		}
+		for _, field := range group.Fields {
+			if err := field.Validate(); err != nil {
+				return errors.Wrapf(err, "field %s", field.Key)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the visible_condition of the group.
+func (g *Group) Validate() error {
+	if err := checkCondition(g.VisibleCondition); err != nil {
+		return errors.Wrap(err, "invalid visible_condition")
+	}
+	return nil
+}
+
+// Validate checks the conditions and templates of the field.
+func (f *Field) Validate() error {
+	if err := checkCondition(f.VisibleCondition); err != nil {
+		return errors.Wrap(err, "invalid visible_condition")
+	}
+	for i, v := range f.Validation {
+		if v == nil {
+			continue
+		}
+		if err := checkCondition(v.Condition); err != nil {
+			return errors.Wrapf(err, "invalid validation condition %d", i+1)
+		}
+	}
+
+	type namedTemplate struct{ name, text string }
+	templates := []namedTemplate{{"title", f.Title}, {"description", f.Description}}
+	if s, ok := f.Value.(string); ok {
+		templates = append(templates, namedTemplate{"value", s})
+	}
+	for _, opt := range f.Options {
+		if opt != nil {
+			templates = append(templates, namedTemplate{"option label", opt.Label})
+		}
+	}
+	if placeholder := f.placeholder(); placeholder != "" {
+		templates = append(templates, namedTemplate{"placeholder", placeholder})
+	}
+	for _, t := range templates {
+		if _, err := ParseTemplate(t.name, t.text); err != nil {
+			return errors.Wrapf(err, "invalid %s template", t.name)
+		}
+	}
+	return nil
+}
+
+// placeholder returns the placeholder of the field's attributes, if it has
+// one.
+func (f *Field) placeholder() string {
+	switch {
+	case f.InputAttributes != nil:
+		return f.InputAttributes.Placeholder
+	case f.TextAttributes != nil:
+		return f.TextAttributes.Placeholder
+	case f.NumberAttributes != nil:
+		return f.NumberAttributes.Placeholder
+	case f.DateAttributes != nil:
+		return f.DateAttributes.Placeholder
+	default:
+		return ""
+	}
+}
+
+func checkCondition(condition string) error {
+	if condition == "" {
+		return nil
+	}
+	_, err := compileCondition(condition)
+	return err
+}
@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    *Form
+		problem string
+	}{
+		{
+			name: "valid",
+			form: &Form{Groups: []*Group{{VisibleCondition: "ok", Fields: []*Field{
+				{Type: "input", Key: "name", Title: "Hello {{ .user }}", Validation: []*Validation{{Condition: "len(name) > 0"}}},
+			}}}},
+		},
+		{
+			name:    "group condition",
+			form:    &Form{Groups: []*Group{{Name: "details", VisibleCondition: "ok ==", Fields: []*Field{}}}},
+			problem: "group details: invalid visible_condition",
+		},
+		{
+			name:    "unnamed group",
+			form:    &Form{Groups: []*Group{{}, {VisibleCondition: "(("}}},
+			problem: "group 2: invalid visible_condition",
+		},
+		{
+			name:    "field condition",
+			form:    &Form{Groups: []*Group{{Fields: []*Field{{Type: "input", Key: "name", VisibleCondition: "a &&"}}}}},
+			problem: "field name: invalid visible_condition",
+		},
+		{
+			name: "validation condition",
+			form: &Form{Groups: []*Group{{Fields: []*Field{{Type: "input", Key: "name", Validation: []*Validation{
+				{Condition: "true"}, {Condition: "len(name) >"},
+			}}}}}},
+			problem: "field name: invalid validation condition 2",
+		},
+		{
+			name:    "title template",
+			form:    &Form{Groups: []*Group{{Fields: []*Field{{Type: "input", Key: "name", Title: "{{ .user "}}}}},
+			problem: "field name: invalid title template",
+		},
+		{
+			name:    "default template",
+			form:    &Form{Groups: []*Group{{Fields: []*Field{{Type: "input", Key: "name", Value: "{{ end }}"}}}}},
+			problem: "field name: invalid value template",
+		},
+		{
+			name: "option label template",
+			form: &Form{Groups: []*Group{{Fields: []*Field{{Type: "select", Key: "pick", Options: []*Option{
+				{Label: "{{ .a", Value: "a"},
+			}}}}}},
+			problem: "field pick: invalid option label template",
+		},
+		{
+			name: "placeholder template",
+			form: &Form{Groups: []*Group{{Fields: []*Field{{Type: "input", Key: "name", InputAttributes: &InputAttributes{
+				Placeholder: "{{ nosuchfunc }}",
+			}}}}}},
+			problem: "field name: invalid placeholder template",
+		},
+	}
+	for _, tt := range tests {
+		err := tt.form.Validate()
+		if tt.problem == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 type UhohCommand struct {
 	*glazedcmds.CommandDescription `yaml:",inline"`
 	Form                           *pkg.Form `yaml:"form"`
+
+	answers map[string]interface{}
 }
 
 var _ glazedcmds.BareCommand = &UhohCommand{}
@@ -28,8 +30,22 @@ func NewUhohCommand(
 	}, nil
 }
 
+// SetAnswers makes Run fill in the form from answers, keyed by field key,
+// instead of showing it (see pkg.Form.Answer).
+func (u *UhohCommand) SetAnswers(answers map[string]interface{}) {
+	u.answers = answers
+}
+
 func (u *UhohCommand) Run(ctx context.Context, parsedLayers *layers.ParsedLayers) error {
-	results, err := u.Form.Run(ctx)
+	if u.answers != nil {
+		ctx = pkg.WithAnswers(ctx, u.answers)
+	}
+	form, err := u.Form.ResolveOptions(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	results, err := form.Run(ctx)
 	if err != nil {
 		return err
 	}
@@ -16,7 +16,12 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-type UhohCommandLoader struct{}
+type UhohCommandLoader struct {
+	// Dir is the directory relative options_from files of the loaded forms
+	// are resolved against, usually the directory of the command file. It
+	// defaults to the working directory.
+	Dir string
+}
 
 func (u *UhohCommandLoader) IsFileSupported(f fs.FS, fileName string) bool {
 	return strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml")
@@ -26,14 +31,18 @@ var _ loaders.CommandLoader = (*UhohCommandLoader)(nil)
 
 // Add this new type
 type fieldWithRawAttributes struct {
-	Type        string            `yaml:"type"`
-	Key         string            `yaml:"key,omitempty"`
-	Title       string            `yaml:"title,omitempty"`
-	Description string            `yaml:"description,omitempty"`
-	Value       interface{}       `yaml:"value,omitempty"`
-	Options     []*pkg.Option     `yaml:"options,omitempty"`
-	Validation  []*pkg.Validation `yaml:"validation,omitempty"`
-	Attributes  yaml.Node         `yaml:"attributes,omitempty"`
+	Type             string            `yaml:"type"`
+	Key              string            `yaml:"key,omitempty"`
+	Title            string            `yaml:"title,omitempty"`
+	Description      string            `yaml:"description,omitempty"`
+	Required         bool              `yaml:"required,omitempty"`
+	Value            interface{}       `yaml:"value,omitempty"`
+	Options          []*pkg.Option     `yaml:"options,omitempty"`
+	OptionsFrom      *pkg.OptionsFrom  `yaml:"options_from,omitempty"`
+	Validation       []*pkg.Validation `yaml:"validation,omitempty"`
+	VisibleCondition string            `yaml:"visible_condition,omitempty"`
+	Prefill          *bool             `yaml:"prefill,omitempty"`
+	Attributes       yaml.Node         `yaml:"attributes,omitempty"`
 }
 
 type UhohCommandDescription struct {
@@ -51,8 +60,9 @@ type UhohCommandDescription struct {
 		Theme string `yaml:"theme,omitempty"`
 
 		Groups []struct {
-			Name   string                   `yaml:"name,omitempty"`
-			Fields []fieldWithRawAttributes `yaml:"fields"`
+			Name             string                   `yaml:"name,omitempty"`
+			VisibleCondition string                   `yaml:"visible_condition,omitempty"`
+			Fields           []fieldWithRawAttributes `yaml:"fields"`
 		} `yaml:"groups"`
 	} `yaml:"form"`
 }
@@ -85,6 +95,7 @@ func (u *UhohCommandLoader) LoadUhohCommandFromReader(
 		Name:   ucd.Form.Name,
 		Theme:  ucd.Form.Theme,
 		Groups: make([]*pkg.Group, len(ucd.Form.Groups)),
+		Dir:    u.Dir,
 	}
 
 	if len(ucd.Form.Groups) == 0 {
@@ -94,8 +105,9 @@ func (u *UhohCommandLoader) LoadUhohCommandFromReader(
 	// Process the fields and convert the raw attributes to the correct type
 	for i, group := range ucd.Form.Groups {
 		form.Groups[i] = &pkg.Group{
-			Name:   group.Name,
-			Fields: make([]*pkg.Field, len(group.Fields)),
+			Name:             group.Name,
+			VisibleCondition: group.VisibleCondition,
+			Fields:           make([]*pkg.Field, len(group.Fields)),
 		}
 		if len(group.Fields) == 0 {
 			return nil, fmt.Errorf("no fields found in group %s", group.Name)
@@ -108,6 +120,9 @@ func (u *UhohCommandLoader) LoadUhohCommandFromReader(
 			form.Groups[i].Fields[j] = &processedField
 		}
 	}
+	if err := form.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid form %s: %w", ucd.Form.Name, err)
+	}
 
 	options_ := []cmds.CommandDescriptionOption{
 		cmds.WithShort(ucd.Short),
@@ -139,58 +154,24 @@ func (u *UhohCommandLoader) LoadUhohCommandFromReader(
 // Add this new function
 func processField(field fieldWithRawAttributes) (pkg.Field, error) {
 	processedField := pkg.Field{
-		Type:        field.Type,
-		Key:         field.Key,
-		Title:       field.Title,
-		Description: field.Description,
-		Value:       field.Value,
-		Options:     field.Options,
-		Validation:  field.Validation,
+		Type:             field.Type,
+		Key:              field.Key,
+		Title:            field.Title,
+		Description:      field.Description,
+		Required:         field.Required,
+		Value:            field.Value,
+		Options:          field.Options,
+		OptionsFrom:      field.OptionsFrom,
+		Validation:       field.Validation,
+		VisibleCondition: field.VisibleCondition,
+		Prefill:          field.Prefill,
 	}
 
-	switch field.Type {
-	case "input":
-		var attrs pkg.InputAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.InputAttributes = &attrs
-	case "text":
-		var attrs pkg.TextAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.TextAttributes = &attrs
-	case "select":
-		var attrs pkg.SelectAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.SelectAttributes = &attrs
-	case "multiselect":
-		var attrs pkg.MultiSelectAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.MultiSelectAttributes = &attrs
-	case "confirm":
-		var attrs pkg.ConfirmAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.ConfirmAttributes = &attrs
-	case "note":
-		var attrs pkg.NoteAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.NoteAttributes = &attrs
-	case "filepicker":
-		var attrs pkg.FilePickerAttributes
-		if err := field.Attributes.Decode(&attrs); err != nil {
-			return pkg.Field{}, err
-		}
-		processedField.FilePickerAttributes = &attrs
+	if err := processedField.DecodeAttributes(&field.Attributes); err != nil {
+		return pkg.Field{}, err
+	}
+	if err := pkg.ValidateOptions(&processedField); err != nil {
+		return pkg.Field{}, err
 	}
 
 	return processedField, nil
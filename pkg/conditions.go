@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// compileCondition compiles a boolean expr condition that is evaluated against
+// the in-progress form values. Keys that are not set yet evaluate to nil.
+func compileCondition(condition string) (*vm.Program, error) {
+	opts := append([]expr.Option{
+		expr.AllowUndefinedVariables(),
+		expr.AsBool(),
+	}, exprFunctions...)
+
+	return expr.Compile(condition, opts...)
+}
+
+// conditionEnv builds the environment for a condition from the raw (unfiltered)
+// form values. Every field is available by its key and under `state`.
+func conditionEnv(values map[string]interface{}) (map[string]interface{}, error) {
+	current, err := extractValues(values, false)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]interface{}, len(current)+2)
+	for k, v := range current {
+		env[k] = v
+	}
+	env["state"] = current
+	return env, nil
+}
+
+// conditionalValue wraps the bound pointer of a field whose visibility depends
+// on a visible_condition, either its own or the one of its group.
+// ExtractFinalValues leaves out fields that are hidden when it is called.
+type conditionalValue struct {
+	ptr     interface{}
+	visible func() bool
+}
+
+// newVisibility compiles a visible_condition into a function that re-evaluates
+// it against the current form values. It returns nil when there is no
+// condition. A condition that fails to evaluate leaves the field visible.
+func newVisibility(condition string, values map[string]interface{}) (func() bool, error) {
+	if condition == "" {
+		return nil, nil
+	}
+
+	program, err := compileCondition(condition)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not compile visible_condition: %s", condition)
+	}
+
+	return func() bool {
+		env, err := conditionEnv(values)
+		if err != nil {
+			log.Warn().Err(err).Str("condition", condition).Msg("Could not evaluate visible_condition")
+			return true
+		}
+		result, err := expr.Run(program, env)
+		if err != nil {
+			log.Warn().Err(err).Str("condition", condition).Msg("Could not evaluate visible_condition")
+			return true
+		}
+		visible, _ := result.(bool)
+		return visible
+	}, nil
+}
+
+// bothVisible combines two visibility functions, either of which may be nil.
+func bothVisible(a, b func() bool) func() bool {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	default:
+		return func() bool { return a() && b() }
+	}
+}
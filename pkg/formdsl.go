@@ -3,11 +3,11 @@ package pkg
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,29 +15,177 @@ type Form struct {
 	Name   string   `yaml:"name,omitempty"`
 	Theme  string   `yaml:"theme,omitempty"`
 	Groups []*Group `yaml:"groups"`
+
+	// Dir is the directory relative options_from files are resolved against.
+	// The loaders set it to the directory of the wizard or command file; if
+	// it is empty, the working directory is used.
+	Dir string `yaml:"-"`
 }
 
 type Group struct {
-	Name   string   `yaml:"name,omitempty"`
-	Fields []*Field `yaml:"fields"`
+	Name             string   `yaml:"name,omitempty"`
+	VisibleCondition string   `yaml:"visible_condition,omitempty"`
+	Fields           []*Field `yaml:"fields"`
 }
 
 type Field struct {
-	Type                  string        `yaml:"type"`
-	Key                   string        `yaml:"key,omitempty"`
-	Title                 string        `yaml:"title,omitempty"`
-	Description           string        `yaml:"description,omitempty"`
-	Required              bool          `yaml:"required,omitempty"`
-	Value                 interface{}   `yaml:"value,omitempty"`
-	Options               []*Option     `yaml:"options,omitempty"`
-	Validation            []*Validation `yaml:"validation,omitempty"`
-	InputAttributes       *InputAttributes
-	TextAttributes        *TextAttributes
-	SelectAttributes      *SelectAttributes
-	MultiSelectAttributes *MultiSelectAttributes
-	ConfirmAttributes     *ConfirmAttributes
-	NoteAttributes        *NoteAttributes
-	FilePickerAttributes  *FilePickerAttributes
+	Type             string        `yaml:"type"`
+	Key              string        `yaml:"key,omitempty"`
+	Title            string        `yaml:"title,omitempty"`
+	Description      string        `yaml:"description,omitempty"`
+	Required         bool          `yaml:"required,omitempty"`
+	Value            interface{}   `yaml:"value,omitempty"`
+	Options          []*Option     `yaml:"options,omitempty"`
+	OptionsFrom      *OptionsFrom  `yaml:"options_from,omitempty"`
+	Validation       []*Validation `yaml:"validation,omitempty"`
+	VisibleCondition string        `yaml:"visible_condition,omitempty"`
+	// Prefill controls whether a wizard state value with the same key replaces
+	// the default value. It defaults to true.
+	Prefill *bool `yaml:"prefill,omitempty"`
+
+	// Type-specific attributes, decoded from the `attributes` block by
+	// DecodeAttributes.
+	InputAttributes       *InputAttributes       `yaml:"-"`
+	TextAttributes        *TextAttributes        `yaml:"-"`
+	SelectAttributes      *SelectAttributes      `yaml:"-"`
+	MultiSelectAttributes *MultiSelectAttributes `yaml:"-"`
+	ConfirmAttributes     *ConfirmAttributes     `yaml:"-"`
+	NoteAttributes        *NoteAttributes        `yaml:"-"`
+	FilePickerAttributes  *FilePickerAttributes  `yaml:"-"`
+	NumberAttributes      *NumberAttributes      `yaml:"-"`
+	DateAttributes        *DateAttributes        `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a field and its type-specific `attributes` block.
+func (f *Field) UnmarshalYAML(node *yaml.Node) error {
+	type fieldAlias Field
+	var raw struct {
+		fieldAlias `yaml:",inline"`
+		Attributes yaml.Node `yaml:"attributes,omitempty"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*f = Field(raw.fieldAlias)
+	if err := ValidateOptions(f); err != nil {
+		return err
+	}
+	return f.DecodeAttributes(&raw.Attributes)
+}
+
+// MarshalYAML writes a field with its type-specific `attributes` block, the
+// inverse of UnmarshalYAML.
+func (f *Field) MarshalYAML() (interface{}, error) {
+	type fieldAlias Field
+	return struct {
+		fieldAlias `yaml:",inline"`
+		Attributes interface{} `yaml:"attributes,omitempty"`
+	}{fieldAlias(*f), f.attributes()}, nil
+}
+
+// attributes returns the attribute struct of the field, or nil.
+func (f *Field) attributes() interface{} {
+	switch {
+	case f.InputAttributes != nil:
+		return f.InputAttributes
+	case f.TextAttributes != nil:
+		return f.TextAttributes
+	case f.SelectAttributes != nil:
+		return f.SelectAttributes
+	case f.MultiSelectAttributes != nil:
+		return f.MultiSelectAttributes
+	case f.ConfirmAttributes != nil:
+		return f.ConfirmAttributes
+	case f.NoteAttributes != nil:
+		return f.NoteAttributes
+	case f.FilePickerAttributes != nil:
+		return f.FilePickerAttributes
+	case f.NumberAttributes != nil:
+		return f.NumberAttributes
+	case f.DateAttributes != nil:
+		return f.DateAttributes
+	default:
+		return nil
+	}
+}
+
+// FieldTypes lists the field types of the form DSL.
+var FieldTypes = []string{
+	"input", "text", "select", "multiselect", "confirm", "note", "filepicker",
+	"number", "integer", "date", "datetime",
+}
+
+// NewFieldAttributes returns an empty attribute struct for a field type, the
+// one DecodeAttributes decodes `attributes` into, or nil for unknown types.
+func NewFieldAttributes(fieldType string) interface{} {
+	switch fieldType {
+	case "input":
+		return &InputAttributes{}
+	case "text":
+		return &TextAttributes{}
+	case "select":
+		return &SelectAttributes{}
+	case "multiselect":
+		return &MultiSelectAttributes{}
+	case "confirm":
+		return &ConfirmAttributes{}
+	case "note":
+		return &NoteAttributes{}
+	case "filepicker":
+		return &FilePickerAttributes{}
+	case "number", "integer":
+		return &NumberAttributes{}
+	case "date", "datetime":
+		return &DateAttributes{}
+	default:
+		return nil
+	}
+}
+
+// DecodeAttributes decodes an `attributes` node into the attribute struct
+// matching the field's type. An empty node leaves the attributes unset.
+func (f *Field) DecodeAttributes(node *yaml.Node) error {
+	if node == nil || node.Kind == 0 {
+		return nil
+	}
+
+	var target interface{}
+	switch f.Type {
+	case "input":
+		f.InputAttributes = &InputAttributes{}
+		target = f.InputAttributes
+	case "text":
+		f.TextAttributes = &TextAttributes{}
+		target = f.TextAttributes
+	case "select":
+		f.SelectAttributes = &SelectAttributes{}
+		target = f.SelectAttributes
+	case "multiselect":
+		f.MultiSelectAttributes = &MultiSelectAttributes{}
+		target = f.MultiSelectAttributes
+	case "confirm":
+		f.ConfirmAttributes = &ConfirmAttributes{}
+		target = f.ConfirmAttributes
+	case "note":
+		f.NoteAttributes = &NoteAttributes{}
+		target = f.NoteAttributes
+	case "filepicker":
+		f.FilePickerAttributes = &FilePickerAttributes{}
+		target = f.FilePickerAttributes
+	case "number", "integer":
+		f.NumberAttributes = &NumberAttributes{}
+		target = f.NumberAttributes
+	case "date", "datetime":
+		f.DateAttributes = &DateAttributes{}
+		target = f.DateAttributes
+	default:
+		return nil
+	}
+
+	if err := node.Decode(target); err != nil {
+		return errors.Wrapf(err, "could not decode attributes of field %s", f.Key)
+	}
+	return nil
 }
 
 type Option struct {
@@ -90,6 +238,27 @@ type NoteAttributes struct {
 	NextLabel      string `yaml:"next_label,omitempty"`
 }
 
+// NumberAttributes configure `number` and `integer` fields. Min, max and step
+// are checked when the value is entered; step is counted from min (or 0).
+type NumberAttributes struct {
+	Min         *float64 `yaml:"min,omitempty"`
+	Max         *float64 `yaml:"max,omitempty"`
+	Step        float64  `yaml:"step,omitempty"`
+	Prompt      string   `yaml:"prompt,omitempty"`
+	Placeholder string   `yaml:"placeholder,omitempty"`
+	CharLimit   int      `yaml:"char_limit,omitempty"`
+}
+
+// DateAttributes configure `date` and `datetime` fields. Format is a Go time
+// layout; min and max are written in that same layout.
+type DateAttributes struct {
+	Format      string `yaml:"format,omitempty"`
+	Min         string `yaml:"min,omitempty"`
+	Max         string `yaml:"max,omitempty"`
+	Prompt      string `yaml:"prompt,omitempty"`
+	Placeholder string `yaml:"placeholder,omitempty"`
+}
+
 type FilePickerAttributes struct {
 	CurrentDirectory string   `yaml:"current_directory,omitempty"`
 	ShowHidden       bool     `yaml:"show_hidden,omitempty"`
@@ -101,14 +270,6 @@ type FilePickerAttributes struct {
 	Height           int      `yaml:"height,omitempty"`
 }
 
-type FieldWithValidation interface {
-	Validate(func(string) error) huh.Field
-}
-
-func addValidation(field huh.Field, validations []*Validation) (huh.Field, error) {
-	return nil, errors.New("not implemented")
-}
-
 // BuildBubbleTeaModel constructs a huh.Form (which implements tea.Model) from the
 // Uhoh Form without running it. It also returns the internal values map that
 // holds pointers to the bound variables. When the returned huh.Form is driven
@@ -123,12 +284,27 @@ func (f *Form) BuildBubbleTeaModel() (*huh.Form, map[string]interface{}, error)
 
 	// Iterate through groups and fields to build the huh Form
 	for _, group := range f.Groups {
+		groupVisible, err := newVisibility(group.VisibleCondition, values)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "group %s", group.Name)
+		}
+
+		// Fields with a visible_condition get a huh.Group of their own, since
+		// huh can only hide whole groups. The fields around them are collected
+		// into groups that share the visibility of the DSL group.
 		huhFields := make([]huh.Field, 0, len(group.Fields))
+		flushFields := func() {
+			if len(huhFields) == 0 {
+				return
+			}
+			huhGroups = append(huhGroups, newHuhGroup(huhFields, groupVisible))
+			huhFields = make([]huh.Field, 0, len(group.Fields))
+		}
 
 		for _, field := range group.Fields {
 			// Initialize target variables and store pointer in map
 			switch field.Type {
-			case "input", "text", "select", "filepicker":
+			case "input", "text", "filepicker":
 				var strValue string
 				if field.Value != nil {
 					if val, ok := field.Value.(string); ok {
@@ -138,32 +314,34 @@ func (f *Form) BuildBubbleTeaModel() (*huh.Form, map[string]interface{}, error)
 					}
 				}
 				values[field.Key] = &strValue
+			case "select":
+				if err := ValidateOptions(field); err != nil {
+					return nil, nil, err
+				}
+				values[field.Key] = newSelectValue(field)
 			case "multiselect":
-				var strSliceValue []string
-				if field.Value != nil {
-					if valSlice, ok := field.Value.([]interface{}); ok {
-						for _, item := range valSlice {
-							strSliceValue = append(strSliceValue, fmt.Sprintf("%v", item))
-						}
-					} else if valStrSlice, ok := field.Value.([]string); ok {
-						strSliceValue = valStrSlice
-					} else {
-						fmt.Printf("Warning: Unexpected type for multiselect default value: %T\n", field.Value)
-					}
+				if err := ValidateOptions(field); err != nil {
+					return nil, nil, err
 				}
-				values[field.Key] = &strSliceValue
+				values[field.Key] = newMultiSelectValue(field)
 			case "confirm":
 				var boolValue bool
 				if field.Value != nil {
 					if val, ok := field.Value.(bool); ok {
 						boolValue = val
 					} else {
-						fmt.Printf("Warning: Unexpected type for confirm default value: %T\n", field.Value)
+						log.Warn().Str("field", field.Key).Msgf("Unexpected type for confirm default value: %T", field.Value)
 					}
 				}
 				values[field.Key] = &boolValue
 			case "note":
 				values[field.Key] = nil
+			case "number", "integer", "date", "datetime":
+				tv, err := newTypedValue(field)
+				if err != nil {
+					return nil, nil, err
+				}
+				values[field.Key] = tv
 			default:
 				return nil, nil, fmt.Errorf("unsupported field type for value initialization: %s", field.Type)
 			}
@@ -233,10 +411,10 @@ func (f *Form) BuildBubbleTeaModel() (*huh.Form, map[string]interface{}, error)
 					huhField = note
 					break
 				}
-				select_ := huh.NewSelect[string]().
+				select_ := huh.NewSelect[int]().
 					Title(field.Title).
 					Options(opts...).
-					Value(values[field.Key].(*string))
+					Value(values[field.Key].(*selectValue).index)
 				if field.SelectAttributes != nil {
 					select_ = select_.Inline(field.SelectAttributes.Inline)
 					if field.SelectAttributes.Height > 0 {
@@ -247,10 +425,10 @@ func (f *Form) BuildBubbleTeaModel() (*huh.Form, map[string]interface{}, error)
 				huhField = select_
 
 			case "multiselect":
-				multiSelect := huh.NewMultiSelect[string]().
+				multiSelect := huh.NewMultiSelect[int]().
 					Title(field.Title).
 					Options(createOptions(field.Options)...).
-					Value(values[field.Key].(*[]string))
+					Value(values[field.Key].(*multiSelectValue).indices)
 				if field.MultiSelectAttributes != nil {
 					if field.MultiSelectAttributes.Limit > 0 {
 						multiSelect = multiSelect.Limit(field.MultiSelectAttributes.Limit)
@@ -316,27 +494,39 @@ func (f *Form) BuildBubbleTeaModel() (*huh.Form, map[string]interface{}, error)
 				}
 				huhField = filePicker
 
+			case "number", "integer", "date", "datetime":
+				huhField = newTypedInput(field, values[field.Key].(*typedValue))
+
 			default:
 				return nil, nil, fmt.Errorf("unsupported field type during huh field creation: %s", field.Type)
 			}
 
-			if len(field.Validation) > 0 {
-				var err error
-				huhField, err = addValidation(huhField, field.Validation)
+			if field.Required || len(field.Validation) > 0 || isTypedField(field.Type) {
+				validator, err := newFieldValidator(field, values)
 				if err != nil {
-					fmt.Printf("Warning: Validation not yet implemented for field %s\n", field.Key)
+					return nil, nil, err
 				}
+				huhField = addValidation(huhField, field.Options, validator.validate)
 			}
 
-			huhFields = append(huhFields, huhField)
-		}
+			fieldVisible, err := newVisibility(field.VisibleCondition, values)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "field %s", field.Key)
+			}
+			visible := bothVisible(groupVisible, fieldVisible)
+			if visible != nil && values[field.Key] != nil {
+				values[field.Key] = &conditionalValue{ptr: values[field.Key], visible: visible}
+			}
 
-		if len(huhFields) == 0 {
-			continue
+			if fieldVisible == nil {
+				huhFields = append(huhFields, huhField)
+				continue
+			}
+			flushFields()
+			huhGroups = append(huhGroups, newHuhGroup([]huh.Field{huhField}, visible))
 		}
 
-		huhGroup := huh.NewGroup(huhFields...)
-		huhGroups = append(huhGroups, huhGroup)
+		flushFields()
 	}
 
 	if len(huhGroups) == 0 {
@@ -357,12 +547,36 @@ func (f *Form) BuildBubbleTeaModel() (*huh.Form, map[string]interface{}, error)
 	return huhForm, values, nil
 }
 
+// newHuhGroup creates a huh.Group that is skipped whenever visible returns
+// false. A nil visible function means the group is always shown.
+func newHuhGroup(fields []huh.Field, visible func() bool) *huh.Group {
+	group := huh.NewGroup(fields...)
+	if visible != nil {
+		group = group.WithHideFunc(func() bool { return !visible() })
+	}
+	return group
+}
+
 // ExtractFinalValues converts the internal values map (which stores pointers)
 // into a plain map. Call this after the returned huh.Form has reached
-// huh.StateCompleted inside your Bubble Tea program.
+// huh.StateCompleted inside your Bubble Tea program. Fields hidden by a
+// visible_condition are left out of the result.
 func ExtractFinalValues(values map[string]interface{}) (map[string]interface{}, error) {
+	return extractValues(values, true)
+}
+
+// extractValues dereferences the values map. When final is set, fields whose
+// visible_condition currently evaluates to false are skipped and typed fields
+// that do not parse are an error; otherwise they are reported as nil.
+func extractValues(values map[string]interface{}, final bool) (map[string]interface{}, error) {
 	finalValues := make(map[string]interface{})
 	for key, valuePtr := range values {
+		if cv, ok := valuePtr.(*conditionalValue); ok {
+			if final && !cv.visible() {
+				continue
+			}
+			valuePtr = cv.ptr
+		}
 		if valuePtr == nil {
 			continue
 		}
@@ -377,6 +591,16 @@ func ExtractFinalValues(values map[string]interface{}) (map[string]interface{},
 			}
 		case *bool:
 			finalValues[key] = *p
+		case *selectValue:
+			finalValues[key] = p.value()
+		case *multiSelectValue:
+			finalValues[key] = p.value()
+		case *typedValue:
+			v, err := p.value()
+			if err != nil && final {
+				return nil, errors.Wrapf(err, "invalid value for field %s", key)
+			}
+			finalValues[key] = v
 		default:
 			return nil, fmt.Errorf("unexpected pointer type in results map for key '%s': %T", key, p)
 		}
@@ -395,333 +619,58 @@ func BuildBubbleTeaModelFromYAML(src []byte) (*huh.Form, map[string]interface{},
 	return f.BuildBubbleTeaModel()
 }
 
-// Run executes the form and returns a map of the input values and an error if any
+// Run executes the form and returns a map of the input values and an error if any.
+// If the user presses BackKey, Run returns ErrGoBack. In a headless context
+// (see WithAnswers), the form is filled in with Answer instead.
 func (f *Form) Run(ctx context.Context) (map[string]interface{}, error) {
-	// Create a map to store pointers to the input values
-	values := make(map[string]interface{})
-
-	// Create huh Form groups
-	var huhGroups []*huh.Group
-
-	// Iterate through groups and fields to build the huh Form
-	for _, group := range f.Groups {
-		huhFields := make([]huh.Field, 0, len(group.Fields))
-
-		for _, field := range group.Fields {
-			// Create the target variable and store its pointer in the map FIRST
-			// Initialize with default or YAML value
-			switch field.Type {
-			case "input", "text", "select", "filepicker":
-				var strValue string
-				if field.Value != nil {
-					// Attempt type assertion, handle potential errors if YAML value isn't a string
-					if val, ok := field.Value.(string); ok {
-						strValue = val
-					} else {
-						// Handle non-string default value for string fields - convert or error?
-						// For now, convert using fmt.Sprintf, might need refinement
-						strValue = fmt.Sprintf("%v", field.Value)
-						// Consider logging a warning here
-					}
-				}
-				values[field.Key] = &strValue // Store pointer
-			case "multiselect":
-				var strSliceValue []string
-				if field.Value != nil {
-					// Attempt type assertion for slice of interfaces, then convert each to string
-					if valSlice, ok := field.Value.([]interface{}); ok {
-						for _, item := range valSlice {
-							strSliceValue = append(strSliceValue, fmt.Sprintf("%v", item))
-						}
-					} else if valStrSlice, ok := field.Value.([]string); ok {
-						// Handle case where YAML already provided []string
-						strSliceValue = valStrSlice
-					} else {
-						// Handle other unexpected types for multiselect default
-						// For now, initialize empty or log warning
-						log.Printf("Warning: Unexpected type for multiselect default value: %T", field.Value)
-					}
-				}
-				values[field.Key] = &strSliceValue // Store pointer
-			case "confirm":
-				var boolValue bool
-				if field.Value != nil {
-					if val, ok := field.Value.(bool); ok {
-						boolValue = val
-					} else {
-						// Handle non-bool default value for confirm field - maybe parse string?
-						// For now, default to false if type is wrong
-						log.Printf("Warning: Unexpected type for confirm default value: %T", field.Value)
-					}
-				}
-				values[field.Key] = &boolValue // Store pointer
-			case "note":
-				// Notes don't store values, but add key to map to avoid nil checks later?
-				values[field.Key] = nil // Or maybe an empty struct?
-			default:
-				return nil, fmt.Errorf("unsupported field type for value initialization: %s", field.Type)
-			}
-
-			// Create the appropriate huh field based on the type, passing the stored pointer
-			var huhField huh.Field
-			switch field.Type {
-			case "input":
-				// value := values[field.Key].(string) // Old way
-				input := huh.NewInput().
-					Title(field.Title).
-					Value(values[field.Key].(*string)) // Pass stored pointer
-				if field.InputAttributes != nil {
-					// ... (rest of input attribute settings remain the same)
-					if field.InputAttributes.Prompt != "" {
-						input = input.Prompt(field.InputAttributes.Prompt)
-					}
-					if field.InputAttributes.CharLimit > 0 {
-						input = input.CharLimit(field.InputAttributes.CharLimit)
-					}
-					if field.InputAttributes.Placeholder != "" {
-						input = input.Placeholder(field.InputAttributes.Placeholder)
-					}
-					if field.InputAttributes.EchoMode != "" {
-						switch field.InputAttributes.EchoMode {
-						case "password":
-							input = input.EchoMode(huh.EchoModePassword)
-						case "none":
-							input = input.EchoMode(huh.EchoModeNone)
-						}
-					}
-				}
-				huhField = input
-				// values[field.Key] = &value // REMOVE THIS
-
-			case "text":
-				// value := values[field.Key].(string) // Old way
-				text := huh.NewText().
-					Title(field.Title).
-					Value(values[field.Key].(*string)) // Pass stored pointer
-				if field.TextAttributes != nil {
-					// ... (rest of text attribute settings remain the same)
-					if field.TextAttributes.Lines > 0 {
-						text = text.Lines(field.TextAttributes.Lines)
-					}
-					if field.TextAttributes.CharLimit > 0 {
-						text = text.CharLimit(field.TextAttributes.CharLimit)
-					}
-					text = text.ShowLineNumbers(field.TextAttributes.ShowLineNumbers)
-					if field.TextAttributes.Placeholder != "" {
-						text = text.Placeholder(field.TextAttributes.Placeholder)
-					}
-					if field.TextAttributes.Editor != "" {
-						text = text.Editor(field.TextAttributes.Editor)
-					}
-					if len(field.TextAttributes.EditorArgs) > 0 {
-						text = text.EditorExtension(strings.Join(field.TextAttributes.EditorArgs, " "))
-					}
-					if field.TextAttributes.EditorExtension != "" {
-						text = text.EditorExtension(field.TextAttributes.EditorExtension)
-					}
-				}
-				huhField = text
-				// values[field.Key] = &value // REMOVE THIS
-
-			case "select":
-				// value := values[field.Key].(string) // Old way
-				select_ := huh.NewSelect[string]().
-					Title(field.Title).
-					Options(createOptions(field.Options)...).
-					Value(values[field.Key].(*string)) // Pass stored pointer
-				if field.SelectAttributes != nil {
-					// ... (rest of select attribute settings remain the same)
-					select_ = select_.Inline(field.SelectAttributes.Inline)
-					if field.SelectAttributes.Height > 0 {
-						select_ = select_.Height(field.SelectAttributes.Height)
-					}
-					select_ = select_.Filtering(field.SelectAttributes.Filterable)
-				}
-				huhField = select_
-				// values[field.Key] = &value // REMOVE THIS
-
-			case "multiselect":
-				// value := values[field.Key].([]string) // Old way
-				multiSelect := huh.NewMultiSelect[string]().
-					Title(field.Title).
-					Options(createOptions(field.Options)...).
-					Value(values[field.Key].(*[]string)) // Pass stored pointer
-				if field.MultiSelectAttributes != nil {
-					// ... (rest of multiselect attribute settings remain the same)
-					if field.MultiSelectAttributes.Limit > 0 {
-						multiSelect = multiSelect.Limit(field.MultiSelectAttributes.Limit)
-					}
-					if field.MultiSelectAttributes.Height > 0 {
-						multiSelect = multiSelect.Height(field.MultiSelectAttributes.Height)
-					}
-					multiSelect = multiSelect.Filterable(field.MultiSelectAttributes.Filterable)
-				}
-				huhField = multiSelect
-				// values[field.Key] = &value // REMOVE THIS
-
-			case "confirm":
-				// value := values[field.Key].(bool) // Old way
-				confirm := huh.NewConfirm().
-					Title(field.Title).
-					Value(values[field.Key].(*bool)) // Pass stored pointer
-				if field.ConfirmAttributes != nil {
-					// ... (rest of confirm attribute settings remain the same)
-					if field.ConfirmAttributes.Affirmative != "" {
-						confirm = confirm.Affirmative(field.ConfirmAttributes.Affirmative)
-					}
-					if field.ConfirmAttributes.Negative != "" {
-						confirm = confirm.Negative(field.ConfirmAttributes.Negative)
-					}
-				}
-				huhField = confirm
-				// values[field.Key] = &value // REMOVE THIS
-
-			case "note":
-				note := huh.NewNote().
-					Title(field.Title).
-					Description(field.Description)
-				if field.NoteAttributes != nil {
-					// ... (rest of note attribute settings remain the same)
-					if field.NoteAttributes.Height > 0 {
-						note = note.Height(field.NoteAttributes.Height)
-					}
-					if field.NoteAttributes.ShowNextButton {
-						note = note.Next(true)
-						if field.NoteAttributes.NextLabel != "" {
-							note = note.NextLabel(field.NoteAttributes.NextLabel)
-						}
-					}
-				}
-				huhField = note
-				// No value to store or remove
-
-			case "filepicker":
-				// value := values[field.Key].(string) // Old way
-				filePicker := huh.NewFilePicker().
-					Title(field.Title).
-					Value(values[field.Key].(*string)) // Pass stored pointer
-				if field.FilePickerAttributes != nil {
-					// ... (rest of filepicker attribute settings remain the same)
-					if field.FilePickerAttributes.CurrentDirectory != "" {
-						filePicker = filePicker.CurrentDirectory(field.FilePickerAttributes.CurrentDirectory)
-					}
-					filePicker = filePicker.
-						ShowHidden(field.FilePickerAttributes.ShowHidden).
-						ShowSize(field.FilePickerAttributes.ShowSize).
-						ShowPermissions(field.FilePickerAttributes.ShowPermissions).
-						FileAllowed(field.FilePickerAttributes.FileAllowed).
-						DirAllowed(field.FilePickerAttributes.DirAllowed)
-					if len(field.FilePickerAttributes.AllowedTypes) > 0 {
-						filePicker = filePicker.AllowedTypes(field.FilePickerAttributes.AllowedTypes)
-					}
-					if field.FilePickerAttributes.Height > 0 {
-						filePicker = filePicker.Height(field.FilePickerAttributes.Height)
-					}
-				}
-				huhField = filePicker
-				// values[field.Key] = &value // REMOVE THIS
-
-			default:
-				// This case should ideally be caught by the initialization switch above
-				return nil, fmt.Errorf("unsupported field type during huh field creation: %s", field.Type)
-			}
-
-			// Add validation if specified
-			// ... (validation logic remains the same) ...
-			if len(field.Validation) > 0 {
-				var err error
-				huhField, err = addValidation(huhField, field.Validation)
-				if err != nil {
-					// validation is not implemented yet
-					// return nil, fmt.Errorf("validation error for field %s: %w", field.Key, err)
-					log.Printf("Warning: Validation not yet implemented for field %s", field.Key)
-				}
-			}
-
-			huhFields = append(huhFields, huhField)
-		} // End field loop
-
-		if len(huhFields) == 0 {
-			// Allow groups with no fields (e.g., for layout purposes if huh supports it?)
-			// Or return error? Let's allow it for now.
-			// log.Printf("Warning: Group '%s' has no fields.", group.Name)
-			continue // Skip creating an empty huh.Group
+	if answers, ok := AnswersFrom(ctx); ok {
+		huhForm, values, err := f.answer(answers)
+		if err != nil {
+			return nil, err
 		}
-
-		// Create the huh Group
-		huhGroup := huh.NewGroup(huhFields...)
-		// TODO: Add Group Title/Name handling if huh supports it directly on groups
-		huhGroups = append(huhGroups, huhGroup)
-	} // End group loop
-
-	// Check if there are any groups to run
-	if len(huhGroups) == 0 {
-		log.Println("Warning: No interactive fields found in the form.")
-		return make(map[string]interface{}), nil // Return empty results if no groups/fields
+		recordView(ctx, huhForm)
+		return values, nil
 	}
 
-	// Create the huh Form
-	huhForm := huh.NewForm(huhGroups...)
+	huhForm, values, err := f.BuildBubbleTeaModel()
+	if err != nil {
+		return nil, err
+	}
 
-	// Set the theme if specified
-	// ... (theme logic remains the same) ...
-	if f.Theme != "" {
-		theme, err := getTheme(f.Theme)
-		if err != nil {
-			return nil, err
-		}
-		huhForm = huhForm.WithTheme(theme)
+	// Check if there are any fields to run
+	if len(values) == 0 {
+		log.Warn().Str("form", f.Name).Msg("No interactive fields found in the form")
+		return make(map[string]interface{}), nil // Return empty results if no groups/fields
 	}
 
-	log.Println("--- Running Form ---") // Debug statement
 	// Run the form
-	err := huhForm.RunWithContext(ctx)
+	err = RunHuhForm(ctx, huhForm)
 	if err != nil {
+		if errors.Is(err, ErrGoBack) {
+			return nil, err
+		}
 		// Check for specific errors like Abort
 		if errors.Is(err, huh.ErrUserAborted) {
-			log.Println("Form aborted by user.")
+			log.Debug().Str("form", f.Name).Msg("Form aborted by user")
 			// Return a specific error or nil with partial results?
 			// For now, return the error.
 			return nil, errors.Wrap(err, "form aborted")
 		}
 		return nil, errors.Wrap(err, "error running huh form")
 	}
-	log.Println("--- Form Finished ---") // Debug statement
 
-	// Extract final values from the pointers in the map
-	finalValues := make(map[string]interface{})
-	for key, valuePtr := range values {
-		if valuePtr == nil { // Handle note fields or potential issues
-			continue
-		}
-		switch p := valuePtr.(type) {
-		case *string:
-			finalValues[key] = *p
-		case *[]string:
-			// Ensure we don't return nil slice if it was empty
-			if *p == nil {
-				finalValues[key] = []string{}
-			} else {
-				finalValues[key] = *p
-			}
-		case *bool:
-			finalValues[key] = *p
-		default:
-			// Should not happen with current types, but handle defensively
-			return nil, fmt.Errorf("unexpected pointer type in results map for key '%s': %T", key, p)
-		}
-	}
-
-	return finalValues, nil
+	return ExtractFinalValues(values)
 }
 
-// Helper function to create huh options from our Option structs
-func createOptions(options []*Option) []huh.Option[string] {
-	var huhOptions []huh.Option[string]
-	for _, opt := range options {
-		huhOptions = append(huhOptions, huh.NewOption(opt.Label, opt.Value.(string)))
+// DefaultValues returns the values the form starts with (defaults, or values
+// pre-filled from a state), as Run would return them if the form was
+// submitted right away.
+func (f *Form) DefaultValues() (map[string]interface{}, error) {
+	_, values, err := f.BuildBubbleTeaModel()
+	if err != nil {
+		return nil, err
 	}
-	return huhOptions
+	return ExtractFinalValues(values)
 }
 
 // Helper function to get the huh theme based on the theme name
@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// Host shows forms, models and progress inside a Bubble Tea program that is
+// already running (such as the wizard program), instead of starting a new
+// program for each of them. Its methods are called from outside the program's
+// event loop and block until the view is done.
+type Host interface {
+	// RunForm shows form until it is submitted or aborted, like RunHuhForm.
+	RunForm(ctx context.Context, form *huh.Form) error
+	// RunModel shows model until it returns ModelDone from a command, and
+	// returns the final model.
+	RunModel(ctx context.Context, model tea.Model) (tea.Model, error)
+	// StartProgress shows a spinner with title and description until stop is
+	// called.
+	StartProgress(title string, description string) (stop func())
+}
+
+// ModelDoneMsg ends a model run with Host.RunModel.
+type ModelDoneMsg struct{}
+
+// ModelDone is the command a hosted model returns instead of tea.Quit.
+func ModelDone() tea.Msg {
+	return ModelDoneMsg{}
+}
+
+type hostKey struct{}
+
+// WithHost returns a context in which RunHuhForm, and the wizard steps, show
+// their views in host.
+func WithHost(ctx context.Context, host Host) context.Context {
+	return context.WithValue(ctx, hostKey{}, host)
+}
+
+// HostFrom returns the host stored in ctx by WithHost, or nil.
+func HostFrom(ctx context.Context) Host {
+	if ctx == nil {
+		return nil
+	}
+	host, _ := ctx.Value(hostKey{}).(Host)
+	return host
+}
@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/go-go-golems/glazed/pkg/helpers/templating"
+	"github.com/pkg/errors"
+)
+
+// Interpolate renders s as a Go text/template against state, with the sprig
+// and glazed helper functions, `shellquote` (see ShellQuote) and
+// `pathescape` (url.PathEscape) available. State keys are accessed as
+// `{{.snake_name}}` or, as in expr conditions, `{{state.snake_name}}`.
+// Top-level keys that are not set render as an empty string. Strings without
+// template actions are returned unchanged.
+func Interpolate(s string, state map[string]interface{}) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := ParseTemplate("interpolate", s)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not parse template %q", s)
+	}
+
+	out, err := ExecuteTemplate(tmpl, state)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not render template %q", s)
+	}
+	return out, nil
+}
+
+// ParseTemplate parses s with the functions available to Interpolate, so
+// longer templates can be checked once (parse errors carry the template name
+// and line number) and rendered later with ExecuteTemplate.
+func ParseTemplate(name string, s string) (*template.Template, error) {
+	return templating.CreateTemplate(name).
+		Option("missingkey=zero").
+		Funcs(template.FuncMap{
+			"state":      func() map[string]interface{} { return nil },
+			"shellquote": shellQuoteValue,
+			"pathescape": func(v interface{}) string { return url.PathEscape(fmt.Sprint(v)) },
+		}).
+		Parse(s)
+}
+
+// ExecuteTemplate renders a template created by ParseTemplate against state.
+// The top-level keys the template reads that are not set, or set to nil,
+// render as an empty string instead of text/template's "<no value>".
+func ExecuteTemplate(tmpl *template.Template, state map[string]interface{}) (string, error) {
+	data := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		data[k] = v
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		for _, key := range TemplateReferences(t.Tree.Root) {
+			if data[key] == nil {
+				data[key] = ""
+			}
+		}
+	}
+
+	// Bind `state` to this rendering's state, on a clone so the parsed
+	// template can be shared.
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"state": func() map[string]interface{} { return data },
+	})
+
+	return templating.RenderTemplate(tmpl, data)
+}
+
+// TemplateReferences returns the state keys a parsed template reads, as
+// `.key`, `$.key` or `state.key`, sorted and without duplicates. Fields
+// inside range and with blocks are relative to another value and are not
+// references.
+func TemplateReferences(node parse.Node) []string {
+	var refs []string
+	walkTemplate(node, &refs)
+	if len(refs) == 0 {
+		return nil
+	}
+	sort.Strings(refs)
+	return slices.Compact(refs)
+}
+
+func walkTemplate(node parse.Node, refs *[]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplate(child, refs)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, refs)
+	case *parse.IfNode:
+		walkTemplate(n.Pipe, refs)
+		walkTemplate(n.List, refs)
+		walkTemplate(n.ElseList, refs)
+	case *parse.RangeNode:
+		walkTemplate(n.Pipe, refs)
+		walkTemplate(n.ElseList, refs)
+	case *parse.WithNode:
+		walkTemplate(n.Pipe, refs)
+		walkTemplate(n.ElseList, refs)
+	case *parse.TemplateNode:
+		walkTemplate(n.Pipe, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTemplate(cmd, refs)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplate(arg, refs)
+		}
+	case *parse.FieldNode:
+		*refs = append(*refs, n.Ident[0])
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			*refs = append(*refs, n.Ident[1])
+		}
+	case *parse.ChainNode:
+		if id, ok := n.Node.(*parse.IdentifierNode); ok && id.Ident == "state" && len(n.Field) > 0 {
+			*refs = append(*refs, n.Field[0])
+			return
+		}
+		walkTemplate(n.Node, refs)
+	}
+}
+
+// InterpolateValue interpolates every string inside v, descending into maps
+// and lists. Other values are returned as they are.
+func InterpolateValue(v interface{}, state map[string]interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case string:
+		return Interpolate(t, state)
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			rendered, err := InterpolateValue(item, state)
+			if err != nil {
+				return nil, errors.Wrapf(err, "key %s", k)
+			}
+			ret[k] = rendered
+		}
+		return ret, nil
+	case []interface{}:
+		ret := make([]interface{}, len(t))
+		for i, item := range t {
+			rendered, err := InterpolateValue(item, state)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = rendered
+		}
+		return ret, nil
+	default:
+		return v, nil
+	}
+}
+
+// Interpolate returns a copy of the form in which field titles, descriptions,
+// string defaults, placeholders and option labels have been rendered against
+// state. See Interpolate for the template syntax.
+func (f *Form) Interpolate(state map[string]interface{}) (*Form, error) {
+	ret := f.clone()
+
+	var err error
+	render := func(s *string) {
+		if err != nil {
+			return
+		}
+		*s, err = Interpolate(*s, state)
+	}
+
+	for _, group := range ret.Groups {
+		for _, field := range group.Fields {
+			render(&field.Title)
+			render(&field.Description)
+			if s, ok := field.Value.(string); ok {
+				render(&s)
+				field.Value = s
+			}
+
+			if len(field.Options) > 0 {
+				options := make([]*Option, len(field.Options))
+				for i, opt := range field.Options {
+					optCopy := *opt
+					render(&optCopy.Label)
+					options[i] = &optCopy
+				}
+				field.Options = options
+			}
+
+			// Attributes are shared with the definition, copy before rendering.
+			if field.InputAttributes != nil {
+				attrs := *field.InputAttributes
+				render(&attrs.Placeholder)
+				field.InputAttributes = &attrs
+			}
+			if field.TextAttributes != nil {
+				attrs := *field.TextAttributes
+				render(&attrs.Placeholder)
+				field.TextAttributes = &attrs
+			}
+			if field.NumberAttributes != nil {
+				attrs := *field.NumberAttributes
+				render(&attrs.Placeholder)
+				field.NumberAttributes = &attrs
+			}
+			if field.DateAttributes != nil {
+				attrs := *field.DateAttributes
+				render(&attrs.Placeholder)
+				field.DateAttributes = &attrs
+			}
+
+			if err != nil {
+				return nil, errors.Wrapf(err, "field %s", field.Key)
+			}
+		}
+	}
+
+	return ret, nil
+}
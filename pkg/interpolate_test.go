@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	state := map[string]interface{}{
+		"name":  "Slytherin",
+		"count": 3,
+		"unset": nil,
+		"repo":  map[string]interface{}{"stars": 42},
+		"tags":  []interface{}{"a", "b"},
+		"path":  "a/b c",
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"no template", "no template"},
+		{"Feeding log for {{.name}}", "Feeding log for Slytherin"},
+		{"{{ state.name }}", "Slytherin"},
+		{"{{ $.name }}", "Slytherin"},
+		{"{{ .repo.stars }} stars", "42 stars"},
+		{"[{{ .missing }}]", "[]"},
+		{"[{{ .unset }}]", "[]"},
+		{"[{{ state.missing }}]", "[]"},
+		{"[{{ .missing | upper }}]", "[]"},
+		{"{{ .missing | default \"none\" }}", "none"},
+		{"{{ if .missing }}yes{{ else }}no{{ end }}", "no"},
+		{"{{ range .tags }}{{ . }}{{ end }}", "ab"},
+		{"{{ $n := .count }}{{ $n }}", "3"},
+		{"repos/{{ .path | pathescape }}", "repos/a%2Fb%20c"},
+		{"?q={{ .path | urlquery }}", "?q=a%2Fb+c"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.template, state)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.template, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.template, tt.expected, got)
+		}
+	}
+
+	if _, err := Interpolate("{{ .name ", state); err == nil || !strings.Contains(err.Error(), "could not parse") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+	if _, err := Interpolate("{{ .name.first }}", state); err == nil {
+		t.Error("expected an error reading a field of a string")
+	}
+}
+
+func TestInterpolateDoesNotChangeState(t *testing.T) {
+	state := map[string]interface{}{"unset": nil}
+	if _, err := Interpolate("{{ .unset }}{{ .missing }}", state); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(state, map[string]interface{}{"unset": nil}) {
+		t.Errorf("expected the state to be left alone, got %#v", state)
+	}
+}
+
+func TestTemplateReferences(t *testing.T) {
+	tests := []struct {
+		template string
+		expected []string
+	}{
+		{"{{ .a }} {{ .b.c }} {{ .a }}", []string{"a", "b"}},
+		{"{{ $.a }} {{ state.b }}", []string{"a", "b"}},
+		{"{{ range .items }}{{ .name }}{{ end }}", []string{"items"}},
+		{"{{ with .repo }}{{ .stars }}{{ else }}{{ .fallback }}{{ end }}", []string{"fallback", "repo"}},
+		{"{{ if .x }}{{ .y }}{{ end }}", []string{"x", "y"}},
+		{"plain", nil},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate("test", tt.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := TemplateReferences(tmpl.Tree.Root)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%q: expected %v, got %v", tt.template, tt.expected, got)
+		}
+	}
+}
+
+func TestInterpolateValue(t *testing.T) {
+	state := map[string]interface{}{"user": "octocat"}
+	got, err := InterpolateValue(map[string]interface{}{
+		"login": "{{ .user }}",
+		"list":  []interface{}{"{{ .user }}!", 3},
+		"count": 3,
+	}, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"login": "octocat",
+		"list":  []interface{}{"octocat!", 3},
+		"count": 3,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+}
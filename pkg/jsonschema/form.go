@@ -0,0 +1,559 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/pkg/errors"
+)
+
+// SchemaForm is a form generated from the JSON Schema of an object. Each
+// scalar property is a field, keyed by its dotted path (`server.port`), and
+// each nested object is a group. Document turns the results of the form back
+// into a document that validates against the schema.
+type SchemaForm struct {
+	Form   *pkg.Form
+	Schema *Schema
+
+	fields    []*schemaField
+	constants []*schemaField
+	objects   [][]string // required objects, created even without fields
+}
+
+// maxDepth bounds the nesting of objects, which also stops recursive
+// schemas.
+const maxDepth = 16
+
+// schemaField maps a field of the form to a property of the schema.
+type schemaField struct {
+	path     []string
+	schema   *Schema
+	items    *Schema // resolved items of an array
+	required bool
+	// kind is how the result of the field is converted: "date", "datetime",
+	// "lines" (a list, one item per line), "const" or "" (as is).
+	kind string
+}
+
+// LoadSchema reads a JSON Schema file.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read schema %s", path)
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "could not parse schema %s", path)
+	}
+	return s, nil
+}
+
+// NewSchemaForm generates the form of an object schema. Properties are mapped
+// to fields as follows:
+//
+//   - enum: select, with the enum values as options
+//   - array of enum: multiselect (minItems and maxItems are enforced)
+//   - boolean: confirm
+//   - string with format date or date-time: date or datetime
+//   - integer and number: integer and number (minimum, maximum, the exclusive
+//     bounds and multipleOf are enforced)
+//   - string: input (minLength, maxLength and pattern are enforced; format
+//     password hides the input)
+//   - array of strings or numbers: text, one item per line
+//   - object: a group (nested objects get their own groups)
+//   - const: no field, the value is written to the document
+//
+// Titles, descriptions, defaults and required properties carry over. Local
+// references (#/definitions/... and #/$defs/...) are resolved; other schemas
+// are an error naming the property.
+func NewSchemaForm(schema *Schema) (*SchemaForm, error) {
+	sf := &SchemaForm{Form: &pkg.Form{Name: schema.Title}, Schema: schema}
+	root, err := sf.resolve(schema, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !isObject(root) {
+		return nil, errors.New("the schema must describe an object with properties")
+	}
+	if err := sf.addObject(root, nil, root.Title, true); err != nil {
+		return nil, err
+	}
+	if len(sf.Form.Groups) == 0 {
+		return nil, errors.New("the schema has no properties to ask for")
+	}
+	return sf, nil
+}
+
+// addObject adds a group with the scalar properties of an object, then the
+// groups of its nested objects.
+func (sf *SchemaForm) addObject(s *Schema, path []string, name string, required bool) error {
+	objectKey := strings.Join(path, ".")
+	if len(path) > maxDepth {
+		return errors.Errorf("property %s is nested too deeply (recursive schemas are not supported)", objectKey)
+	}
+	if len(s.Properties) == 0 && len(path) > 0 {
+		return errors.Errorf("property %s: objects without properties are not supported", objectKey)
+	}
+	if required && len(path) > 0 {
+		sf.objects = append(sf.objects, path)
+	}
+
+	group := &pkg.Group{Name: name}
+	type nested struct {
+		schema   *Schema
+		path     []string
+		required bool
+	}
+	var objects []nested
+
+	requiredKeys := map[string]bool{}
+	for _, key := range s.Required {
+		requiredKeys[key] = true
+	}
+
+	for _, key := range s.PropertyNames() {
+		propPath := append(append([]string{}, path...), key)
+		prop, err := sf.resolve(s.Properties[key], propPath)
+		if err != nil {
+			return err
+		}
+		if isObject(prop) {
+			objects = append(objects, nested{prop, propPath, required && requiredKeys[key]})
+			continue
+		}
+		field, err := sf.newField(prop, propPath, requiredKeys[key])
+		if err != nil {
+			return err
+		}
+		if field != nil {
+			group.Fields = append(group.Fields, field)
+		}
+	}
+
+	if len(group.Fields) > 0 {
+		sf.Form.Groups = append(sf.Form.Groups, group)
+	}
+	for _, o := range objects {
+		name := o.schema.Title
+		if name == "" {
+			name = strings.Join(o.path, ".")
+		}
+		if err := sf.addObject(o.schema, o.path, name, o.required); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newField maps a scalar or list property to a field. Constants return no
+// field.
+func (sf *SchemaForm) newField(s *Schema, path []string, required bool) (*pkg.Field, error) {
+	key := strings.Join(path, ".")
+	mapped := &schemaField{path: path, schema: s, required: required}
+
+	if s.Const != nil {
+		mapped.kind = "const"
+		sf.constants = append(sf.constants, mapped)
+		return nil, nil
+	}
+
+	field := &pkg.Field{
+		Key:         key,
+		Title:       s.Title,
+		Description: s.Description,
+		Required:    required,
+		Value:       s.Default,
+	}
+	if field.Title == "" {
+		field.Title = path[len(path)-1]
+	}
+
+	switch {
+	case len(s.Enum) > 0:
+		field.Type = "select"
+		field.Options = enumOptions(s.Enum)
+
+	case s.Type.Has("array"):
+		items, err := sf.resolve(s.Items, path)
+		if err != nil {
+			return nil, err
+		}
+		if items == nil {
+			return nil, errors.Errorf("property %s: arrays without items are not supported", key)
+		}
+		mapped.items = items
+		switch {
+		case len(items.Enum) > 0:
+			field.Type = "multiselect"
+			field.Options = enumOptions(items.Enum)
+			if s.MaxItems != nil {
+				field.MultiSelectAttributes = &pkg.MultiSelectAttributes{Limit: *s.MaxItems}
+			}
+		case items.Type.Has("string") || items.Type.Has("integer") || items.Type.Has("number"):
+			field.Type = "text"
+			mapped.kind = "lines"
+			if list, ok := s.Default.([]interface{}); ok {
+				lines := make([]string, len(list))
+				for i, item := range list {
+					lines[i] = fmt.Sprintf("%v", item)
+				}
+				field.Value = strings.Join(lines, "\n")
+			}
+			if field.Description == "" {
+				field.Description = "One item per line"
+			}
+		default:
+			return nil, errors.Errorf("property %s: only arrays of enums, strings and numbers are supported", key)
+		}
+		// Lists entered as text are counted by non-blank line
+		count := "len(value)"
+		if mapped.kind == "lines" {
+			count = `len(filter(split(value, "\n"), {trim(#) != ""}))`
+		}
+		if s.MinItems != nil && *s.MinItems > 0 {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("%s < %d", count, *s.MinItems),
+				Error:     fmt.Sprintf("Must have at least %d items", *s.MinItems),
+			})
+		}
+		if s.MaxItems != nil {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("%s > %d", count, *s.MaxItems),
+				Error:     fmt.Sprintf("Must have at most %d items", *s.MaxItems),
+			})
+		}
+
+	case s.Type.Has("boolean"):
+		field.Type = "confirm"
+		// A required boolean must be present, not true
+		field.Required = false
+
+	case s.Type.Has("integer"), s.Type.Has("number"):
+		field.Type = "number"
+		if s.Type.Has("integer") {
+			field.Type = "integer"
+		}
+		if s.Minimum != nil || s.Maximum != nil {
+			field.NumberAttributes = &pkg.NumberAttributes{Min: s.Minimum, Max: s.Maximum}
+		}
+		if bound, ok := exclusiveBound(s.ExclusiveMinimum, s.Minimum); ok {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("value <= %v", bound),
+				Error:     fmt.Sprintf("Must be greater than %v", bound),
+			})
+		}
+		if bound, ok := exclusiveBound(s.ExclusiveMaximum, s.Maximum); ok {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("value >= %v", bound),
+				Error:     fmt.Sprintf("Must be less than %v", bound),
+			})
+		}
+		if s.MultipleOf != nil && *s.MultipleOf > 0 {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("abs(value / %v - round(value / %v)) > 1e-9", *s.MultipleOf, *s.MultipleOf),
+				Error:     fmt.Sprintf("Must be a multiple of %v", *s.MultipleOf),
+			})
+		}
+
+	case s.Type.Has("string") && s.Format == "date":
+		field.Type = "date"
+		mapped.kind = "date"
+
+	case s.Type.Has("string") && s.Format == "date-time":
+		field.Type = "datetime"
+		mapped.kind = "datetime"
+		// Show an RFC 3339 default in the layout the field is typed in
+		if text, ok := s.Default.(string); ok {
+			if t, err := time.Parse(time.RFC3339, text); err == nil {
+				field.Value = t.Local()
+			}
+		}
+
+	case s.Type.Has("string") || len(s.Type) == 0:
+		field.Type = "input"
+		attributes := &pkg.InputAttributes{}
+		if s.MaxLength != nil {
+			attributes.CharLimit = *s.MaxLength
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("len(value) > %d", *s.MaxLength),
+				Error:     fmt.Sprintf("Must be at most %d characters", *s.MaxLength),
+			})
+		}
+		if s.Format == "password" {
+			attributes.EchoMode = "password"
+		}
+		if *attributes != (pkg.InputAttributes{}) {
+			field.InputAttributes = attributes
+		}
+		if s.MinLength != nil && *s.MinLength > 0 {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("len(value) < %d", *s.MinLength),
+				Error:     fmt.Sprintf("Must be at least %d characters", *s.MinLength),
+			})
+		}
+		if s.Pattern != "" {
+			field.Validation = append(field.Validation, &pkg.Validation{
+				Condition: fmt.Sprintf("not (value matches %s)", strconv.Quote(s.Pattern)),
+				Error:     fmt.Sprintf("Must match %s", s.Pattern),
+			})
+		}
+
+	default:
+		return nil, errors.Errorf("property %s: type %s is not supported", key, strings.Join(s.Type, ", "))
+	}
+
+	sf.fields = append(sf.fields, mapped)
+	return field, nil
+}
+
+// resolve follows local references and unwraps nullable schemas (a type list
+// with null, or anyOf/oneOf with a null alternative) and single allOf. The
+// title, description and default of the outer schema win.
+func (sf *SchemaForm) resolve(s *Schema, path []string) (*Schema, error) {
+	for depth := 0; s != nil; depth++ {
+		if depth > maxDepth {
+			return nil, errors.Errorf("property %s: too many nested references", strings.Join(path, "."))
+		}
+		switch {
+		case s.Ref != "":
+			target, err := sf.lookup(s.Ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, "property %s", strings.Join(path, "."))
+			}
+			s = withAnnotations(target, s)
+		case len(s.Type) > 1 && s.Type.Has("null"):
+			nonNull := *s
+			nonNull.Type = nil
+			for _, t := range s.Type {
+				if t != "null" {
+					nonNull.Type = append(nonNull.Type, t)
+				}
+			}
+			s = &nonNull
+		case len(s.AnyOf) > 0 || len(s.OneOf) > 0:
+			var chosen *Schema
+			for _, a := range append(append([]*Schema{}, s.AnyOf...), s.OneOf...) {
+				if a == nil || (len(a.Type) == 1 && a.Type[0] == "null") {
+					continue
+				}
+				if chosen != nil {
+					return nil, errors.Errorf("property %s: anyOf and oneOf are only supported to make a property nullable", strings.Join(path, "."))
+				}
+				chosen = a
+			}
+			if chosen == nil {
+				return nil, errors.Errorf("property %s: anyOf and oneOf without a non-null alternative are not supported", strings.Join(path, "."))
+			}
+			s = withAnnotations(chosen, s)
+		case len(s.AllOf) == 1 && len(s.Type) == 0 && len(s.Properties) == 0:
+			s = withAnnotations(s.AllOf[0], s)
+		default:
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// withAnnotations returns a copy of target with the title, description and
+// default of from, where set.
+func withAnnotations(target *Schema, from *Schema) *Schema {
+	ret := *target
+	if from.Title != "" {
+		ret.Title = from.Title
+	}
+	if from.Description != "" {
+		ret.Description = from.Description
+	}
+	if from.Default != nil {
+		ret.Default = from.Default
+	}
+	return &ret
+}
+
+// lookup returns the definition a local reference points to.
+func (sf *SchemaForm) lookup(ref string) (*Schema, error) {
+	var defs map[string]*Schema
+	var name string
+	switch {
+	case strings.HasPrefix(ref, "#/definitions/"):
+		defs, name = sf.Schema.Definitions, strings.TrimPrefix(ref, "#/definitions/")
+	case strings.HasPrefix(ref, "#/$defs/"):
+		defs, name = sf.Schema.Defs, strings.TrimPrefix(ref, "#/$defs/")
+	default:
+		return nil, errors.Errorf("unsupported reference %s: only #/definitions/ and #/$defs/ references are supported", ref)
+	}
+	target, ok := defs[name]
+	if !ok || target == nil {
+		return nil, errors.Errorf("reference %s not found", ref)
+	}
+	return target, nil
+}
+
+// Document turns the results of the form into a document for the schema.
+// Fields left empty are left out unless they are required, dates are written
+// in the formats of the schema, lists entered one item per line are split,
+// and constants are added.
+func (sf *SchemaForm) Document(values map[string]interface{}) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	for _, path := range sf.objects {
+		if _, err := objectAt(doc, path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range sf.constants {
+		if err := setPath(doc, c.path, c.schema.Const); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range sf.fields {
+		key := strings.Join(f.path, ".")
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		value, err := f.convert(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s", key)
+		}
+		if isEmpty(value) && !f.required {
+			continue
+		}
+		if err := setPath(doc, f.path, value); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// convert turns a form result into the JSON value of the property.
+func (f *schemaField) convert(value interface{}) (interface{}, error) {
+	switch f.kind {
+	case "date":
+		if t, ok := value.(time.Time); ok {
+			return t.Format("2006-01-02"), nil
+		}
+	case "datetime":
+		if t, ok := value.(time.Time); ok {
+			return t.Format(time.RFC3339), nil
+		}
+	case "lines":
+		s, ok := value.(string)
+		if !ok {
+			return value, nil
+		}
+		items := f.items
+		if items == nil {
+			items = &Schema{}
+		}
+		list := []interface{}{}
+		for _, line := range strings.Split(s, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			switch {
+			case items.Type.Has("integer"):
+				i, err := strconv.ParseInt(line, 10, 64)
+				if err != nil {
+					return nil, errors.Errorf("%q is not a whole number", line)
+				}
+				list = append(list, i)
+			case items.Type.Has("number"):
+				n, err := strconv.ParseFloat(line, 64)
+				if err != nil {
+					return nil, errors.Errorf("%q is not a number", line)
+				}
+				list = append(list, n)
+			default:
+				list = append(list, line)
+			}
+		}
+		return list, nil
+	}
+	return value, nil
+}
+
+// isEmpty reports whether a form result is a blank answer.
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// objectAt returns the object at path in doc, creating it if needed.
+func objectAt(doc map[string]interface{}, path []string) (map[string]interface{}, error) {
+	current := doc
+	for i, key := range path {
+		next, ok := current[key]
+		if !ok {
+			m := map[string]interface{}{}
+			current[key] = m
+			current = m
+			continue
+		}
+		m, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+		current = m
+	}
+	return current, nil
+}
+
+func setPath(doc map[string]interface{}, path []string, value interface{}) error {
+	parent, err := objectAt(doc, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	parent[path[len(path)-1]] = value
+	return nil
+}
+
+// exclusiveBound returns the exclusive bound of a property: a number since
+// draft 6, or a flag making minimum or maximum exclusive in draft 4.
+func exclusiveBound(exclusive interface{}, inclusive *float64) (float64, bool) {
+	switch v := exclusive.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v && inclusive != nil {
+			return *inclusive, true
+		}
+	}
+	return 0, false
+}
+
+// enumOptions returns an option per enum value. null is left out, as a
+// select cannot leave its value unset.
+func enumOptions(values []interface{}) []*pkg.Option {
+	options := make([]*pkg.Option, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		options = append(options, &pkg.Option{Label: fmt.Sprintf("%v", v), Value: v})
+	}
+	return options
+}
+
+// isObject reports whether a property is a nested object, rather than a
+// field.
+func isObject(s *Schema) bool {
+	return s != nil && (s.Type.Has("object") || (len(s.Type) == 0 && len(s.Properties) > 0))
+}
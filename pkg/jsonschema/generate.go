@@ -0,0 +1,250 @@
+package jsonschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/cmds"
+	"github.com/go-go-golems/uhoh/pkg/wizard"
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of file a schema can be generated for.
+const (
+	KindForm    = "form"
+	KindCommand = "command"
+	KindWizard  = "wizard"
+)
+
+// StepTypes lists the step types of the wizard DSL, in the order their
+// schemas are listed.
+var StepTypes = []string{"form", "decision", "action", "info", "summary"}
+
+var (
+	formType        = reflect.TypeOf(pkg.Form{})
+	fieldType       = reflect.TypeOf(pkg.Field{})
+	formStepType    = reflect.TypeOf(steps.FormStep{})
+	wizardType      = reflect.TypeOf(wizard.Wizard{})
+	wizardStepsType = reflect.TypeOf(steps.WizardSteps{})
+	commandType     = reflect.TypeOf(cmds.UhohCommandDescription{})
+	yamlNodeType    = reflect.TypeOf(yaml.Node{})
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// requiredKeys are the keys that must be set, by type. Other keys are
+// optional: YAML leaves them at their zero value.
+var requiredKeys = map[reflect.Type][]string{
+	formType:                             {"groups"},
+	reflect.TypeOf(pkg.Group{}):          {"fields"},
+	fieldType:                            {"type"},
+	reflect.TypeOf(pkg.Option{}):         {"label", "value"},
+	reflect.TypeOf(pkg.Validation{}):     {"condition"},
+	reflect.TypeOf(steps.BaseStep{}):     {"id", "type"},
+	reflect.TypeOf(steps.ActionStep{}):   {"action_type"},
+	reflect.TypeOf(steps.DecisionStep{}): {"target_key"},
+	wizardType:                           {"steps"},
+	commandType:                          {"name", "form"},
+}
+
+// Generate returns the JSON Schema of a form, command or wizard file. The
+// schema is built from the Go types the files are decoded into, so it follows
+// them as they change.
+func Generate(kind string) (*Schema, error) {
+	g := &generator{defs: map[string]*Schema{}}
+
+	var root *Schema
+	switch kind {
+	case KindForm:
+		root = g.structSchema(formType)
+		root.Title = "uhoh form"
+	case KindCommand:
+		root = g.structSchema(commandType)
+		root.Properties["form"] = g.schemaFor(formType)
+		root.Title = "uhoh command"
+	case KindWizard:
+		root = g.structSchema(wizardType)
+		root.Title = "uhoh wizard"
+	default:
+		return nil, errors.Errorf("unknown schema kind %s (expected %s, %s or %s)", kind, KindForm, KindCommand, KindWizard)
+	}
+
+	root.Schema = Draft
+	if len(g.defs) > 0 {
+		root.Definitions = g.defs
+	}
+	return root, nil
+}
+
+type generator struct {
+	defs map[string]*Schema
+}
+
+// schemaFor returns the schema of a value of type t. Named structs of this
+// module become definitions.
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case fieldType:
+		return g.ref("Field", g.fieldSchema)
+	case wizardStepsType:
+		return g.stepsSchema()
+	case yamlNodeType:
+		return &Schema{}
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		// Decoded by hand; the schema is not known.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t.Name(), func() *Schema { return g.structSchema(t) })
+	default:
+		// Interfaces hold any value.
+		return &Schema{}
+	}
+}
+
+// ref returns a reference to the definition name, building it the first
+// time. The definition is registered before it is built, so recursive types
+// terminate.
+func (g *generator) ref(name string, build func() *Schema) *Schema {
+	if _, ok := g.defs[name]; !ok {
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *build()
+	}
+	return &Schema{Ref: "#/definitions/" + name}
+}
+
+// structSchema returns the schema of a struct decoded from YAML: its keys
+// are the yaml tags of its fields, inline fields included, and other keys
+// are not allowed.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 Types{"object"},
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	g.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	s.Required = append(s.Required, requiredKeys[t]...)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			inner := f.Type
+			for inner.Kind() == reflect.Ptr {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct {
+				g.addFields(s, inner)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		s.Properties[name] = g.schemaFor(f.Type)
+	}
+}
+
+// fieldSchema is the schema of a form field. Its `attributes` depend on its
+// type, one if/then per field type.
+func (g *generator) fieldSchema() *Schema {
+	s := g.structSchema(fieldType)
+	s.Properties["type"] = &Schema{Type: Types{"string"}, Enum: stringsToValues(pkg.FieldTypes)}
+	s.Properties["attributes"] = &Schema{Type: Types{"object"}}
+	for _, ft := range pkg.FieldTypes {
+		attributes := g.schemaFor(reflect.TypeOf(pkg.NewFieldAttributes(ft)))
+		s.AllOf = append(s.AllOf, &Schema{
+			If: &Schema{
+				Properties: map[string]*Schema{"type": {Const: ft}},
+				Required:   []string{"type"},
+			},
+			Then: &Schema{Properties: map[string]*Schema{"attributes": attributes}},
+		})
+	}
+	return s
+}
+
+// stepsSchema is the schema of the steps of a wizard, discriminated by
+// their `type`.
+func (g *generator) stepsSchema() *Schema {
+	item := &Schema{
+		Type:       Types{"object"},
+		Required:   []string{"type"},
+		Properties: map[string]*Schema{"type": {Type: Types{"string"}, Enum: stringsToValues(StepTypes)}},
+	}
+	for _, stepType := range StepTypes {
+		t := reflect.TypeOf(steps.NewStep(stepType)).Elem()
+		item.AllOf = append(item.AllOf, &Schema{
+			If: &Schema{
+				Properties: map[string]*Schema{"type": {Const: stepType}},
+				Required:   []string{"type"},
+			},
+			Then: g.ref(t.Name(), func() *Schema { return g.stepSchema(stepType, t) }),
+		})
+	}
+	return &Schema{Type: Types{"array"}, Items: item}
+}
+
+func (g *generator) stepSchema(stepType string, t reflect.Type) *Schema {
+	s := g.structSchema(t)
+	s.Properties["type"] = &Schema{Const: stepType}
+	if t == formStepType {
+		// A full form, or the simplified schema (see FormStep.UnmarshalYAML)
+		simple := g.structSchema(reflect.TypeOf(struct {
+			Fields []struct {
+				Name     string `yaml:"name"`
+				Label    string `yaml:"label"`
+				Type     string `yaml:"type"`
+				Required bool   `yaml:"required,omitempty"`
+			} `yaml:"fields"`
+		}{}))
+		simple.Required = []string{"fields"}
+		s.Properties["form"] = &Schema{AnyOf: []*Schema{g.schemaFor(formType), simple}}
+	}
+	return s
+}
+
+func stringsToValues(s []string) []interface{} {
+	ret := make([]interface{}, len(s))
+	for i, v := range s {
+		ret[i] = v
+	}
+	return ret
+}
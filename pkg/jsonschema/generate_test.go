@@ -0,0 +1,275 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/uhoh/pkg/lint"
+	"gopkg.in/yaml.v3"
+)
+
+// exampleFiles are the form, command and wizard files of the repository.
+var exampleFiles = []string{
+	"../../examples/*.yaml",
+	"../../examples/*/*.yaml",
+	"../../cmd/uhoh/cmds/examples/*.yaml",
+	"../../cmd/uhoh/examples/wizard/*.yaml",
+	"../../cmd/uhoh/examples/wizard/*/*.yaml",
+	"../../pkg/wizard/examples/*.yaml",
+}
+
+// TestGenerateValidatesExamples generates the schema of every kind and
+// checks the example files of the repository against them, so that the
+// schemas do not reject files uhoh runs.
+func TestGenerateValidatesExamples(t *testing.T) {
+	schemas := map[string]*Schema{}
+	for _, kind := range []string{KindForm, KindCommand, KindWizard} {
+		schema, err := Generate(kind)
+		if err != nil {
+			t.Fatalf("could not generate the %s schema: %v", kind, err)
+		}
+		// The schema must survive JSON, as it is printed as JSON.
+		data, err := json.Marshal(schema)
+		if err != nil {
+			t.Fatalf("could not encode the %s schema: %v", kind, err)
+		}
+		decoded := &Schema{}
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatalf("could not decode the %s schema: %v", kind, err)
+		}
+		schemas[kind] = decoded
+	}
+
+	checked := map[string]int{}
+	for _, pattern := range exampleFiles {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range paths {
+			if strings.HasSuffix(path, ".answers.yaml") || strings.HasSuffix(path, ".scenarios.yaml") {
+				continue
+			}
+			kind, doc := loadExample(t, path)
+			checked[kind]++
+			v := &validator{root: schemas[kind]}
+			v.validate(schemas[kind], doc, "")
+			for _, problem := range v.problems {
+				t.Errorf("%s (%s): %s", path, kind, problem)
+			}
+		}
+	}
+
+	for _, kind := range []string{KindForm, KindCommand, KindWizard} {
+		if checked[kind] == 0 {
+			t.Errorf("no example %s files were checked", kind)
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		kind    string
+		doc     string
+		problem string
+	}{
+		{KindForm, `groups: [{fields: [{type: input, key: a, titel: A}]}]`, "unknown key titel"},
+		{KindForm, `groups: [{fields: [{key: a}]}]`, "missing required key type"},
+		{KindForm, `groups: [{fields: [{type: slider, key: a}]}]`, "not one of"},
+		{KindForm, `groups: [{fields: [{type: input, key: a, attributes: {limit: 3}}]}]`, "unknown key limit"},
+		{KindCommand, `name: c`, "missing required key form"},
+		{KindWizard, `steps: [{id: a, type: action}]`, "missing required key action_type"},
+		{KindWizard, `steps: [{id: a, type: info, content: x, target_key: b}]`, "unknown key target_key"},
+	}
+	for _, tt := range tests {
+		schema, err := Generate(tt.kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := &validator{root: schema}
+		v.validate(schema, decodeYAML(t, []byte(tt.doc)), "")
+		if !slices.ContainsFunc(v.problems, func(p string) bool { return strings.Contains(p, tt.problem) }) {
+			t.Errorf("%s %q: expected a problem containing %q, got %v", tt.kind, tt.doc, tt.problem, v.problems)
+		}
+	}
+}
+
+func TestGenerateUnknownKind(t *testing.T) {
+	if _, err := Generate("workflow"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+func loadExample(t *testing.T, path string) (string, interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		return lint.DetectKind(root.Content[0]), decodeYAML(t, data)
+	}
+	return lint.DetectKind(&root), decodeYAML(t, data)
+}
+
+// decodeYAML decodes a YAML document into the values JSON would decode it
+// into, which is what the schemas describe.
+func decodeYAML(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ret interface{}
+	if err := json.Unmarshal(encoded, &ret); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+// validator checks a document against the keywords the generator uses. It
+// is no general JSON Schema validator.
+type validator struct {
+	root     *Schema
+	problems []string
+}
+
+func (v *validator) fail(path string, format string, args ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// matches reports whether doc is valid against s, without recording
+// problems.
+func (v *validator) matches(s *Schema, doc interface{}) bool {
+	sub := &validator{root: v.root}
+	sub.validate(s, doc, "")
+	return len(sub.problems) == 0
+}
+
+func (v *validator) validate(s *Schema, doc interface{}, path string) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		def, ok := v.root.Definitions[name]
+		if !ok {
+			v.fail(path, "unresolved $ref %s", s.Ref)
+			return
+		}
+		v.validate(def, doc, path)
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(typ string) bool { return hasType(doc, typ) }) {
+		v.fail(path, "expected %s, got %T", strings.Join(s.Type, " or "), doc)
+		return
+	}
+	if s.Enum != nil && !slices.ContainsFunc(s.Enum, func(e interface{}) bool { return reflect.DeepEqual(e, doc) }) {
+		v.fail(path, "%v is not one of %v", doc, s.Enum)
+	}
+	if s.Const != nil && !reflect.DeepEqual(s.Const, doc) {
+		v.fail(path, "expected %v, got %v", s.Const, doc)
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := d[key]; !ok {
+				v.fail(path, "missing required key %s", key)
+			}
+		}
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prop, ok := s.Properties[key]; ok {
+				v.validate(prop, d[key], path+"/"+key)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					v.fail(path, "unknown key %s", key)
+				}
+			case map[string]interface{}:
+				var sub Schema
+				data, _ := json.Marshal(additional)
+				if err := json.Unmarshal(data, &sub); err != nil {
+					v.fail(path, "invalid additionalProperties: %v", err)
+					continue
+				}
+				v.validate(&sub, d[key], path+"/"+key)
+			case *Schema:
+				v.validate(additional, d[key], path+"/"+key)
+			}
+		}
+	case []interface{}:
+		for i, item := range d {
+			v.validate(s.Items, item, fmt.Sprintf("%s/%d", path, i))
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		v.validate(sub, doc, path)
+	}
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *Schema) bool { return v.matches(sub, doc) }) {
+		// Report why the first schema did not match, as it is the usual one.
+		first := &validator{root: v.root}
+		first.validate(s.AnyOf[0], doc, path)
+		v.fail(path, "does not match any of the allowed schemas: %s", strings.Join(first.problems, "; "))
+	}
+	if s.If != nil {
+		if v.matches(s.If, doc) {
+			v.validate(s.Then, doc, path)
+		} else {
+			v.validate(s.Else, doc, path)
+		}
+	}
+}
+
+func hasType(doc interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := doc.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := doc.([]interface{})
+		return ok
+	case "string":
+		_, ok := doc.(string)
+		return ok
+	case "boolean":
+		_, ok := doc.(bool)
+		return ok
+	case "number":
+		_, ok := doc.(float64)
+		return ok
+	case "integer":
+		f, ok := doc.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return doc == nil
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+// Package jsonschema generates JSON Schemas for the uhoh YAML formats, so
+// editors can complete and validate form, command and wizard files, and turns
+// the JSON Schema of an object into a form that fills it in.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas, the one
+// yaml-language-server supports best.
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema, limited to the keywords uhoh uses.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type    Types         `json:"type,omitempty"`
+	Format  string        `json:"format,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+	Const   interface{}   `json:"const,omitempty"`
+	Default interface{}   `json:"default,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // bool or *Schema
+
+	Items       *Schema `json:"items,omitempty"`
+	MinItems    *int    `json:"minItems,omitempty"`
+	MaxItems    *int    `json:"maxItems,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+	Minimum          *float64    `json:"minimum,omitempty"`
+	Maximum          *float64    `json:"maximum,omitempty"`
+	ExclusiveMinimum interface{} `json:"exclusiveMinimum,omitempty"` // number, or bool in draft 4
+	ExclusiveMaximum interface{} `json:"exclusiveMaximum,omitempty"` // number, or bool in draft 4
+	MultipleOf       *float64    `json:"multipleOf,omitempty"`
+	MinLength        *int        `json:"minLength,omitempty"`
+	MaxLength        *int        `json:"maxLength,omitempty"`
+	Pattern          string      `json:"pattern,omitempty"`
+
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	AllOf []*Schema `json:"allOf,omitempty"`
+	If    *Schema   `json:"if,omitempty"`
+	Then  *Schema   `json:"then,omitempty"`
+	Else  *Schema   `json:"else,omitempty"`
+
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+
+	// PropertyOrder lists the keys of Properties in the order of the parsed
+	// document. It is empty for generated schemas.
+	PropertyOrder []string `json:"-"`
+}
+
+// UnmarshalJSON parses a schema and records the order of its properties.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type alias Schema
+	var a alias
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*s = Schema(a)
+	if len(s.Properties) == 0 {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	order, err := objectKeys(raw["properties"])
+	if err != nil {
+		return errors.Wrap(err, "could not read properties")
+	}
+	s.PropertyOrder = order
+	return nil
+}
+
+// PropertyNames returns the keys of Properties, in document order if known
+// and sorted otherwise.
+func (s *Schema) PropertyNames() []string {
+	if len(s.PropertyOrder) == len(s.Properties) {
+		return s.PropertyOrder
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// objectKeys returns the keys of a JSON object in document order.
+func objectKeys(data json.RawMessage) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, errors.Errorf("expected an object key, got %v", token)
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Types is the `type` keyword, a single type or a list of types.
+type Types []string
+
+// MarshalJSON writes a single type as a string.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts a string or a list of strings.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Wrap(err, "type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// Has reports whether name is one of the types.
+func (t Types) Has(name string) bool {
+	for _, s := range t {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/cmds"
+	"gopkg.in/yaml.v3"
+)
+
+// lintForm checks a form file.
+func (l *linter) lintForm(root *yaml.Node) {
+	l.checkKeys(root, formType)
+
+	var form pkg.Form
+	if err := root.Decode(&form); err != nil {
+		l.report(root, SeverityError, RuleLoad, "could not load form: %v", err)
+		return
+	}
+	l.lintFormData(&form, root, "")
+}
+
+// lintCommand checks a command file, loading it like `uhoh run-command`.
+func (l *linter) lintCommand(data []byte, root *yaml.Node) {
+	l.checkCommandKeys(root)
+
+	loader := &cmds.UhohCommandLoader{}
+	commands, err := loader.LoadUhohCommandFromReader(bytes.NewReader(data), nil, nil)
+	if err != nil {
+		l.report(root, SeverityError, RuleLoad, "could not load command: %v", err)
+		return
+	}
+	for _, c := range commands {
+		if uc, ok := c.(*cmds.UhohCommand); ok && uc.Form != nil {
+			l.lintFormData(uc.Form, mappingValue(root, "form"), "")
+		}
+	}
+}
+
+// lintFormData checks the conditions and fields of a loaded form. node is
+// the YAML of the form, used to position issues.
+func (l *linter) lintFormData(form *pkg.Form, node *yaml.Node, stepID string) {
+	keys := map[string]bool{}
+	for gi, group := range form.Groups {
+		groupNode := sequenceItem(mappingValue(node, "groups"), gi)
+		if err := group.Validate(); err != nil {
+			name := group.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", gi+1)
+			}
+			l.report(keyOr(groupNode, "visible_condition"), SeverityError, RuleInvalidForm,
+				"group %s: %v", name, err).StepID = stepID
+		}
+
+		for fi, field := range group.Fields {
+			fieldNode := formFieldNode(node, groupNode, fi)
+
+			if err := field.Validate(); err != nil {
+				l.report(fieldNode, SeverityError, RuleInvalidForm,
+					"field %s: %v", field.Key, err).StepID = stepID
+			}
+
+			if (field.Type == "select" || field.Type == "multiselect") && len(field.Options) == 0 && field.OptionsFrom == nil {
+				l.report(keyOr(fieldNode, "type"), SeverityError, RuleSelectWithoutOptions,
+					"%s field %s has neither options nor options_from", field.Type, field.Key).StepID = stepID
+			}
+
+			if field.Key == "" {
+				continue
+			}
+			if keys[field.Key] {
+				l.report(keyOr(fieldNode, "key"), SeverityError, RuleDuplicateFieldKey,
+					"field key %s is used more than once in the form", field.Key).StepID = stepID
+			}
+			keys[field.Key] = true
+		}
+	}
+}
+
+// formFieldNode returns the YAML of the fi-th field of a group. Simplified
+// form steps have their fields directly under the form.
+func formFieldNode(formNode *yaml.Node, groupNode *yaml.Node, fi int) *yaml.Node {
+	if groupNode != nil {
+		return sequenceItem(mappingValue(groupNode, "fields"), fi)
+	}
+	return sequenceItem(mappingValue(formNode, "fields"), fi)
+}
@@ -0,0 +1,255 @@
+package lint
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/cmds"
+	"github.com/go-go-golems/uhoh/pkg/wizard"
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	formType        = reflect.TypeOf(pkg.Form{})
+	fieldType       = reflect.TypeOf(pkg.Field{})
+	wizardType      = reflect.TypeOf(wizard.Wizard{})
+	wizardStepsType = reflect.TypeOf(steps.WizardSteps{})
+	formStepType    = reflect.TypeOf(steps.FormStep{})
+	commandType     = reflect.TypeOf(cmds.UhohCommandDescription{})
+	yamlNodeType    = reflect.TypeOf(yaml.Node{})
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// simpleFormStep is the simplified schema of a form step's `form` block,
+// `fields` with a name, label, type and required flag.
+type simpleFormStep struct {
+	Fields []struct {
+		Name     string `yaml:"name"`
+		Label    string `yaml:"label"`
+		Type     string `yaml:"type"`
+		Required bool   `yaml:"required,omitempty"`
+	} `yaml:"fields"`
+}
+
+// checkKeys reports the keys of node that decoding it into a value of type t
+// silently ignores. Values decoded by custom unmarshalers are only checked
+// for the types of this module whose schema is known.
+func (l *linter) checkKeys(node *yaml.Node, t reflect.Type) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case fieldType:
+		l.checkFieldKeys(node)
+		return
+	case wizardStepsType:
+		l.checkStepsKeys(node)
+		return
+	case formStepType:
+		l.checkFormStepKeys(node)
+		return
+	case yamlNodeType:
+		return
+	}
+	if t != wizardType && reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	if t.PkgPath() != "" && !strings.HasPrefix(t.PkgPath(), "github.com/go-go-golems/uhoh") {
+		// Types of other modules (glazed parameter definitions and layers)
+		// have their own schema.
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		l.checkStructKeys(node, yamlFields(t), nil)
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range node.Content {
+			l.checkKeys(item, t.Elem())
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			l.checkKeys(node.Content[i], t.Elem())
+		}
+	}
+}
+
+// checkStructKeys reports keys of a mapping that are not in fields and walks
+// the values of the known ones. overrides replaces the type a key is checked
+// against.
+func (l *linter) checkStructKeys(node *yaml.Node, fields map[string]reflect.Type, overrides map[string]reflect.Type) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			continue
+		}
+		if t, ok := overrides[key.Value]; ok {
+			l.checkKeys(value, t)
+			continue
+		}
+		t, ok := fields[key.Value]
+		if !ok {
+			l.reportUnknownKey(key, fields, overrides)
+			continue
+		}
+		l.checkKeys(value, t)
+	}
+}
+
+func (l *linter) reportUnknownKey(key *yaml.Node, fields map[string]reflect.Type, overrides map[string]reflect.Type) {
+	known := make([]string, 0, len(fields)+len(overrides))
+	for name := range fields {
+		known = append(known, name)
+	}
+	for name := range overrides {
+		if _, ok := fields[name]; !ok {
+			known = append(known, name)
+		}
+	}
+	sort.Strings(known)
+
+	if suggestion := closest(key.Value, known); suggestion != "" {
+		l.report(key, SeverityError, RuleUnknownKey, "unknown key %q (did you mean %q?)", key.Value, suggestion)
+		return
+	}
+	l.report(key, SeverityError, RuleUnknownKey, "unknown key %q, expected one of: %s", key.Value, strings.Join(known, ", "))
+}
+
+// checkFieldKeys checks a form field, whose `attributes` depend on its type.
+func (l *linter) checkFieldKeys(node *yaml.Node) {
+	fields := yamlFields(fieldType)
+	overrides := map[string]reflect.Type{"attributes": yamlNodeType}
+	l.checkStructKeys(node, fields, overrides)
+
+	attributes := mappingValue(node, "attributes")
+	if attributes == nil {
+		return
+	}
+	typeNode := mappingValue(node, "type")
+	if typeNode == nil {
+		return
+	}
+	if attrs := pkg.NewFieldAttributes(typeNode.Value); attrs != nil {
+		l.checkKeys(attributes, reflect.TypeOf(attrs))
+	}
+}
+
+// checkStepsKeys checks each step against the struct of its type. Steps of
+// unknown types are reported when the wizard is loaded.
+func (l *linter) checkStepsKeys(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, stepNode := range node.Content {
+		typeNode := mappingValue(stepNode, "type")
+		if typeNode == nil {
+			continue
+		}
+		step := steps.NewStep(typeNode.Value)
+		if step == nil {
+			continue
+		}
+		l.checkKeys(stepNode, reflect.TypeOf(step))
+	}
+}
+
+// checkFormStepKeys checks a form step, whose `form` is either a full form or
+// the simplified schema with `fields` (see FormStep.UnmarshalYAML).
+func (l *linter) checkFormStepKeys(node *yaml.Node) {
+	formSchema := formType
+	if form := mappingValue(node, "form"); mappingValue(form, "groups") == nil && mappingValue(form, "fields") != nil {
+		formSchema = reflect.TypeOf(simpleFormStep{})
+	}
+	l.checkStructKeys(node, yamlFields(formStepType), map[string]reflect.Type{"form": formSchema})
+}
+
+// checkCommandKeys checks a command file. Its form is checked as a full form,
+// so that field attributes are checked against the field type.
+func (l *linter) checkCommandKeys(root *yaml.Node) {
+	l.checkStructKeys(root, yamlFields(commandType), map[string]reflect.Type{"form": formType})
+}
+
+// yamlFields returns the YAML keys of a struct type and the type of their
+// values, following inline fields.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			inner := f.Type
+			for inner.Kind() == reflect.Ptr {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct {
+				for k, v := range yamlFields(inner) {
+					fields[k] = v
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// closest returns the candidate nearest to s if it is close enough to be a
+// likely typo, or "".
+func closest(s string, candidates []string) string {
+	best, bestDistance := "", len(s)/2+1
+	for _, c := range candidates {
+		if d := levenshtein(s, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,216 @@
+// Package lint checks uhoh wizard, command and form files without running
+// them: unknown keys, conditions and templates that do not compile, fields and
+// steps that cannot work as written, and references that nothing provides.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of an issue. Errors are mistakes that break the file at load or
+// run time; warnings are likely mistakes that depend on how the file is run.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rules reported by the linter.
+const (
+	RuleSyntax               = "syntax"
+	RuleLoad                 = "load"
+	RuleUnknownKey           = "unknown-key"
+	RuleDuplicateFieldKey    = "duplicate-field-key"
+	RuleInvalidCondition     = "invalid-condition"
+	RuleInvalidForm          = "invalid-form"
+	RuleUnknownStateKey      = "unknown-state-key"
+	RuleUnreachableStep      = "unreachable-step"
+	RuleUnregisteredCallback = "unregistered-callback"
+	RuleSelectWithoutOptions = "select-without-options"
+)
+
+// Issue is a problem found in a file. Line and Column are 1-based and zero
+// when the issue has no position.
+type Issue struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+	StepID   string   `json:"step_id,omitempty"`
+}
+
+func (i Issue) String() string {
+	pos := i.File
+	if i.Line > 0 {
+		pos = fmt.Sprintf("%s:%d:%d", i.File, i.Line, i.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", pos, i.Severity, i.Message, i.Rule)
+}
+
+// Options describe how the linted files are run.
+type Options struct {
+	// Callbacks are the callback and action function names registered by the
+	// program that runs the wizard. Other names are reported.
+	Callbacks []string
+	// StateKeys are keys passed as initial state, which steps may reference
+	// without an earlier step setting them.
+	StateKeys []string
+}
+
+// Kind of a linted file.
+const (
+	KindWizard  = "wizard"
+	KindCommand = "command"
+	KindForm    = "form"
+)
+
+// DetectKind guesses the kind of a file from its top-level keys: wizards have
+// steps, commands a form, and forms groups.
+func DetectKind(root *yaml.Node) string {
+	switch {
+	case mappingValue(root, "steps") != nil:
+		return KindWizard
+	case mappingValue(root, "form") != nil:
+		return KindCommand
+	default:
+		return KindForm
+	}
+}
+
+// LintFile checks the wizard, command or form file at path. The returned
+// error is only set if the file cannot be read; problems in the file are
+// issues, sorted by position.
+func LintFile(path string, opts Options) ([]Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+
+	l := &linter{file: path, opts: opts}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		l.report(nil, SeverityError, RuleSyntax, "%v", err)
+		return l.issues, nil
+	}
+	root := documentRoot(&doc)
+	if root == nil || root.Kind != yaml.MappingNode {
+		l.report(root, SeverityError, RuleSyntax, "expected a mapping at the top level")
+		return l.issues, nil
+	}
+
+	switch DetectKind(root) {
+	case KindWizard:
+		l.lintWizard(path, root)
+	case KindCommand:
+		l.lintCommand(data, root)
+	default:
+		l.lintForm(root)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Message < b.Message
+	})
+	return l.issues, nil
+}
+
+// HasErrors reports whether any issue is an error.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	file   string
+	opts   Options
+	issues []Issue
+}
+
+// report adds an issue positioned at node (which may be nil).
+func (l *linter) report(node *yaml.Node, severity Severity, rule string, format string, args ...interface{}) *Issue {
+	issue := Issue{
+		File:     l.file,
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if node != nil {
+		issue.Line = node.Line
+		issue.Column = node.Column
+	}
+	l.issues = append(l.issues, issue)
+	return &l.issues[len(l.issues)-1]
+}
+
+// documentRoot returns the top-level node of a parsed document.
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// mappingKey returns the key node of key in a mapping node, or nil.
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// keyOr returns the key node of key in node if present, else node itself, to
+// position an issue as close as possible.
+func keyOr(node *yaml.Node, key string) *yaml.Node {
+	if k := mappingKey(node, key); k != nil {
+		return k
+	}
+	return node
+}
+
+// sequenceItem returns the i-th item of a sequence node, or nil.
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
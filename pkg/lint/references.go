@@ -0,0 +1,100 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/go-go-golems/uhoh/pkg"
+)
+
+// exprReferences returns the state keys an expr condition reads: its
+// identifiers, except functions it calls and variables it declares with let.
+// Conditions that do not parse have no references.
+func exprReferences(condition string) []string {
+	if condition == "" {
+		return nil
+	}
+	tree, err := parser.Parse(condition)
+	if err != nil {
+		return nil
+	}
+
+	v := &identifierVisitor{excluded: map[string]bool{}}
+	ast.Walk(&tree.Node, v)
+
+	var refs []string
+	for _, name := range v.identifiers {
+		if !v.excluded[name] {
+			refs = append(refs, name)
+		}
+	}
+	return uniqueSorted(refs)
+}
+
+type identifierVisitor struct {
+	identifiers []string
+	callees     []string
+	excluded    map[string]bool
+}
+
+func (v *identifierVisitor) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.IdentifierNode:
+		v.identifiers = append(v.identifiers, n.Value)
+	case *ast.CallNode:
+		if callee, ok := n.Callee.(*ast.IdentifierNode); ok {
+			v.excluded[callee.Value] = true
+			v.callees = append(v.callees, callee.Value)
+		}
+	case *ast.VariableDeclaratorNode:
+		v.excluded[n.Name] = true
+	}
+}
+
+// templateReferences returns the state keys a template reads (see
+// pkg.TemplateReferences). Templates that do not parse have no references.
+func templateReferences(s string) []string {
+	if !strings.Contains(s, "{{") {
+		return nil
+	}
+	tmpl, err := pkg.ParseTemplate("lint", s)
+	if err != nil || tmpl.Tree == nil {
+		return nil
+	}
+	return pkg.TemplateReferences(tmpl.Tree.Root)
+}
+
+// valueReferences returns the template references of every string inside v,
+// descending into maps and lists like pkg.InterpolateValue.
+func valueReferences(v interface{}) []string {
+	var refs []string
+	switch t := v.(type) {
+	case string:
+		refs = templateReferences(t)
+	case map[string]interface{}:
+		for _, item := range t {
+			refs = append(refs, valueReferences(item)...)
+		}
+	case []interface{}:
+		for _, item := range t {
+			refs = append(refs, valueReferences(item)...)
+		}
+	}
+	return uniqueSorted(refs)
+}
+
+func uniqueSorted(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	sort.Strings(s)
+	ret := s[:1]
+	for _, item := range s[1:] {
+		if item != ret[len(ret)-1] {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,309 @@
+package lint
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/go-go-golems/uhoh/pkg/wizard"
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"gopkg.in/yaml.v3"
+)
+
+// lintWizard checks a wizard file. The semantic checks run on the wizard as
+// LoadWizard returns it, so they are skipped if it does not load.
+func (l *linter) lintWizard(path string, root *yaml.Node) {
+	l.checkKeys(root, wizardType)
+
+	wz, err := wizard.LoadWizard(path)
+	if err != nil {
+		l.report(nil, SeverityError, RuleLoad, "could not load wizard: %v", err)
+		return
+	}
+
+	stepsNode := mappingValue(root, "steps")
+	graph := wz.Graph()
+	reachable := graph.Reachable(graph.Start)
+	fieldSteps := map[string]string{} // field key -> first step asking for it
+
+	for i, step := range wz.Steps {
+		id := step.ID()
+		node := sequenceItem(stepsNode, i)
+
+		if !reachable[id] {
+			l.report(keyOr(node, "id"), SeverityWarning, RuleUnreachableStep,
+				"step %s can never run: no step leads to it", id).StepID = id
+		}
+
+		if err := checkSkipCondition(step.SkipCondition()); err != nil {
+			l.report(keyOr(node, "skip_condition"), SeverityError, RuleInvalidCondition,
+				"invalid skip_condition of step %s: %v", id, err).StepID = id
+		}
+
+		l.checkCallbacks(step, node)
+
+		if fs, ok := step.(*steps.FormStep); ok {
+			formNode := mappingValue(node, "form")
+			l.lintFormData(&fs.FormData, formNode, id)
+			for _, group := range fs.FormData.Groups {
+				for _, field := range group.Fields {
+					if field.Key == "" {
+						continue
+					}
+					if first, ok := fieldSteps[field.Key]; ok && first != id {
+						l.report(keyOr(formNode, "fields"), SeverityWarning, RuleDuplicateFieldKey,
+							"field key %s of step %s is also asked for by step %s, whose answer it overwrites",
+							field.Key, id, first).StepID = id
+						continue
+					}
+					fieldSteps[field.Key] = id
+				}
+			}
+		}
+	}
+
+	l.checkReferences(wz, graph, stepsNode)
+}
+
+// checkSkipCondition compiles a skip condition the way the runner does.
+// Variables are not known before the run, and functions are registered by
+// the program running the wizard, so both are accepted.
+func checkSkipCondition(condition string) error {
+	if condition == "" {
+		return nil
+	}
+	tree, err := parser.Parse(condition)
+	if err != nil {
+		return err
+	}
+	opts := []expr.Option{expr.AllowUndefinedVariables(), expr.AsBool()}
+	v := &identifierVisitor{excluded: map[string]bool{}}
+	ast.Walk(&tree.Node, v)
+	for _, name := range uniqueSorted(v.callees) {
+		opts = append(opts, expr.Function(name, func(params ...interface{}) (interface{}, error) {
+			return nil, nil
+		}))
+	}
+	_, err = expr.Compile(condition, opts...)
+	return err
+}
+
+// checkCallbacks reports callbacks and action functions of a step that are
+// not in Options.Callbacks.
+func (l *linter) checkCallbacks(step steps.Step, node *yaml.Node) {
+	registered := map[string]bool{}
+	for _, name := range l.opts.Callbacks {
+		registered[name] = true
+	}
+
+	check := func(key string, name string) {
+		if name == "" || registered[name] {
+			return
+		}
+		l.report(keyOr(node, key), SeverityWarning, RuleUnregisteredCallback,
+			"%s callback %s of step %s is not registered", key, name, step.ID()).StepID = step.ID()
+	}
+	check("before", step.BeforeCallback())
+	check("after", step.AfterCallback())
+	check("validation", step.ValidationCallback())
+	check("navigation", step.NavigationCallback())
+	if as, ok := step.(*steps.ActionStep); ok && as.ActionType == "function" {
+		check("function_name", as.FunctionName)
+	}
+}
+
+// reference is a state key read by a step, and the key of the step where it
+// is read.
+type reference struct {
+	key   string
+	where string
+	// fieldsOnly marks references from form conditions, which only see the
+	// fields of their form and not the rest of the state.
+	fieldsOnly bool
+}
+
+// checkReferences reports state keys read by a step that neither a step
+// running before it, global_state nor Options.StateKeys provide.
+func (l *linter) checkReferences(wz *wizard.Wizard, graph *wizard.Graph, stepsNode *yaml.Node) {
+	base := map[string]bool{}
+	for key := range wz.GlobalState {
+		base[key] = true
+	}
+	for _, key := range l.opts.StateKeys {
+		base[key] = true
+	}
+
+	reach := map[string]map[string]bool{}
+	for _, step := range wz.Steps {
+		reach[step.ID()] = graph.Reachable(step.ID())
+	}
+
+	for i, step := range wz.Steps {
+		id := step.ID()
+		provided := map[string]bool{}
+		for key := range base {
+			provided[key] = true
+		}
+		for _, other := range wz.Steps {
+			if other.ID() == id && !inCycle(graph, reach, id) {
+				continue
+			}
+			if !reach[other.ID()][id] {
+				continue
+			}
+			for _, key := range stepOutputs(other) {
+				provided[key] = true
+			}
+		}
+
+		node := sequenceItem(stepsNode, i)
+		reported := map[string]bool{}
+		for _, ref := range stepReferences(step) {
+			if ref.fieldsOnly {
+				if reported[ref.key] {
+					continue
+				}
+				reported[ref.key] = true
+				l.report(keyOr(node, "form"), SeverityWarning, RuleUnknownStateKey,
+					"step %s reads %s in %s, but form conditions only see the fields of the form and it has no field %s",
+					id, ref.key, ref.where, ref.key).StepID = id
+				continue
+			}
+			if provided[ref.key] || reported[ref.key] {
+				continue
+			}
+			reported[ref.key] = true
+			l.report(keyOr(node, ref.where), SeverityWarning, RuleUnknownStateKey,
+				"step %s reads %s in %s, but no step before it, global_state or initial state sets it",
+				id, ref.key, ref.where).StepID = id
+		}
+	}
+}
+
+// inCycle reports whether a step can run again after itself.
+func inCycle(graph *wizard.Graph, reach map[string]map[string]bool, id string) bool {
+	for _, edge := range graph.Edges {
+		if edge.From != id {
+			continue
+		}
+		if edge.To == wizard.AnyStep || reach[edge.To][id] {
+			return true
+		}
+	}
+	return false
+}
+
+// stepOutputs returns the state keys a step sets.
+func stepOutputs(step steps.Step) []string {
+	var keys []string
+	switch s := step.(type) {
+	case *steps.FormStep:
+		for _, group := range s.FormData.Groups {
+			for _, field := range group.Fields {
+				if field.Key != "" {
+					keys = append(keys, field.Key)
+				}
+			}
+		}
+	case *steps.DecisionStep:
+		keys = append(keys, s.TargetKey)
+	case *steps.ActionStep:
+		if s.OutputKey != "" {
+			keys = append(keys, s.OutputKey)
+		}
+		for key := range s.ResponseMapping {
+			keys = append(keys, key)
+		}
+		if s.OnError != nil {
+			errorKey := s.OnError.ErrorKey
+			if errorKey == "" {
+				errorKey = s.ID() + "_error"
+			}
+			keys = append(keys, errorKey)
+		}
+	}
+	return keys
+}
+
+// stepReferences returns the state keys a step reads in its templates,
+// skip condition, summary sections, options_from and form conditions. Form
+// conditions only return keys that are not fields of the form.
+func stepReferences(step steps.Step) []reference {
+	var refs []reference
+	add := func(where string, keys []string) {
+		for _, key := range keys {
+			refs = append(refs, reference{key: key, where: where})
+		}
+	}
+
+	bs := step.GetBaseStep()
+	add("title", templateReferences(bs.StepTitle))
+	add("description", templateReferences(bs.StepDescription))
+	add("skip_condition", exprReferences(bs.StepSkipCondition))
+
+	switch s := step.(type) {
+	case *steps.InfoStep:
+		add("content", templateReferences(s.Content))
+	case *steps.SummaryStep:
+		add("template", templateReferences(s.Template))
+		for _, section := range s.Sections {
+			for _, field := range section.Fields {
+				key, _, _ := strings.Cut(field, ".")
+				add("sections", []string{key})
+			}
+		}
+	case *steps.ActionStep:
+		add("arguments", valueReferences(s.Arguments))
+		add("command", templateReferences(s.Command))
+		add("workdir", templateReferences(s.WorkDir))
+		for _, v := range s.Env {
+			add("env", templateReferences(v))
+		}
+		add("url", templateReferences(s.URL))
+		for _, v := range s.Headers {
+			add("headers", templateReferences(v))
+		}
+		add("body", valueReferences(s.Body))
+	case *steps.FormStep:
+		fields := map[string]bool{}
+		for _, group := range s.FormData.Groups {
+			for _, field := range group.Fields {
+				fields[field.Key] = true
+			}
+		}
+		addCondition := func(where string, condition string, excluded ...string) {
+			for _, key := range exprReferences(condition) {
+				if fields[key] || key == "state" || slices.Contains(excluded, key) {
+					continue
+				}
+				refs = append(refs, reference{key: key, where: where, fieldsOnly: true})
+			}
+		}
+
+		for _, group := range s.FormData.Groups {
+			addCondition("visible_condition", group.VisibleCondition)
+			for _, field := range group.Fields {
+				addCondition("visible_condition", field.VisibleCondition)
+				for _, v := range field.Validation {
+					if v != nil {
+						addCondition("validation", v.Condition, "value")
+					}
+				}
+				add("form", templateReferences(field.Title))
+				add("form", templateReferences(field.Description))
+				if value, ok := field.Value.(string); ok {
+					add("form", templateReferences(value))
+				}
+				for _, option := range field.Options {
+					add("form", templateReferences(option.Label))
+				}
+				if field.OptionsFrom != nil && field.OptionsFrom.State != "" {
+					add("form", []string{field.OptionsFrom.State})
+				}
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/pkg/errors"
+)
+
+// ErrGoBack is returned when the user asks to return to the previous wizard
+// step instead of submitting the current one.
+var ErrGoBack = errors.New("go back to the previous step")
+
+// BackKey is the key binding that leaves a form with ErrGoBack.
+var BackKey = key.NewBinding(
+	key.WithKeys("ctrl+b"),
+	key.WithHelp("ctrl+b", "back"),
+)
+
+// backModel wraps a huh form and quits the program when BackKey is pressed.
+type backModel struct {
+	*huh.Form
+	back bool
+}
+
+var _ tea.Model = &backModel{}
+
+func (m *backModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, BackKey) {
+		m.back = true
+		return m, tea.Quit
+	}
+	_, cmd := m.Form.Update(msg)
+	return m, cmd
+}
+
+func (m *backModel) View() string {
+	if m.back {
+		return ""
+	}
+	return m.Form.View()
+}
+
+// RunHuhForm runs a huh form like huh.Form.RunWithContext, except that
+// pressing BackKey leaves the form and returns ErrGoBack. Aborting the form
+// returns huh.ErrUserAborted. If ctx carries a Host, the form is shown there.
+// In a headless context (see WithAnswers) the form is submitted as is without
+// being shown (only recorded, see WithViewRecorder), so callers whose forms
+// ask for input read their answer with AnswersFrom instead.
+func RunHuhForm(ctx context.Context, form *huh.Form) error {
+	if IsHeadless(ctx) {
+		recordView(ctx, form)
+		return nil
+	}
+	if host := HostFrom(ctx); host != nil {
+		return host.RunForm(ctx, form)
+	}
+
+	form.SubmitCmd = tea.Quit
+	form.CancelCmd = tea.Quit
+
+	m := &backModel{Form: form}
+	if _, err := tea.NewProgram(m, tea.WithContext(ctx), tea.WithReportFocus()).Run(); err != nil {
+		return errors.Wrap(err, "error running form")
+	}
+
+	switch {
+	case m.back:
+		return ErrGoBack
+	case form.State == huh.StateAborted:
+		return huh.ErrUserAborted
+	default:
+		return nil
+	}
+}
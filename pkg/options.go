@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/charmbracelet/huh"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// selectValue binds a select field to the index of the chosen option, so the
+// option value keeps its YAML type (string, number, bool, map, ...) instead of
+// having to be a comparable string.
+type selectValue struct {
+	options []*Option
+	index   *int
+}
+
+func (sv *selectValue) value() interface{} {
+	return optionValueAt(sv.options, *sv.index)
+}
+
+// multiSelectValue binds a multiselect field to the indices of the chosen
+// options.
+type multiSelectValue struct {
+	options []*Option
+	indices *[]int
+}
+
+// value returns the chosen option values. Lists of string options are returned
+// as []string; any other option type is returned as []interface{}.
+func (mv *multiSelectValue) value() interface{} {
+	return optionValuesAt(mv.options, *mv.indices)
+}
+
+func optionValueAt(options []*Option, index int) interface{} {
+	if index < 0 || index >= len(options) {
+		return nil
+	}
+	return options[index].Value
+}
+
+func optionValuesAt(options []*Option, indices []int) interface{} {
+	if optionsAreStrings(options) {
+		ret := make([]string, 0, len(indices))
+		for _, i := range indices {
+			if s, ok := optionValueAt(options, i).(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+
+	ret := make([]interface{}, 0, len(indices))
+	for _, i := range indices {
+		if i >= 0 && i < len(options) {
+			ret = append(ret, options[i].Value)
+		}
+	}
+	return ret
+}
+
+func optionsAreStrings(options []*Option) bool {
+	for _, opt := range options {
+		if _, ok := opt.Value.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// newSelectValue creates the binding for a select field, preselecting the
+// option that matches the field's default value.
+func newSelectValue(field *Field) *selectValue {
+	index := optionIndex(field.Options, field.Value)
+	return &selectValue{options: field.Options, index: &index}
+}
+
+// newMultiSelectValue creates the binding for a multiselect field,
+// preselecting the options that match the field's default values.
+func newMultiSelectValue(field *Field) *multiSelectValue {
+	indices := []int{}
+	if field.Value != nil {
+		v := reflect.ValueOf(field.Value)
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				if idx := optionIndex(field.Options, v.Index(i).Interface()); idx >= 0 {
+					indices = append(indices, idx)
+				}
+			}
+		} else {
+			log.Warn().Str("field", field.Key).Msgf("Unexpected type for multiselect default value: %T", field.Value)
+		}
+	}
+	return &multiSelectValue{options: field.Options, indices: &indices}
+}
+
+// optionIndex returns the index of the option whose value equals value, or -1.
+// Values are compared deeply first, then by their string representation so a
+// default of "3" still selects an option with value 3.
+func optionIndex(options []*Option, value interface{}) int {
+	if value == nil {
+		return -1
+	}
+	for i, opt := range options {
+		if reflect.DeepEqual(opt.Value, value) {
+			return i
+		}
+	}
+	for i, opt := range options {
+		if fmt.Sprintf("%v", opt.Value) == fmt.Sprintf("%v", value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// createOptions creates huh options whose values are the indices of the DSL
+// options.
+func createOptions(options []*Option) []huh.Option[int] {
+	huhOptions := make([]huh.Option[int], 0, len(options))
+	for i, opt := range options {
+		huhOptions = append(huhOptions, huh.NewOption(opt.Label, i))
+	}
+	return huhOptions
+}
+
+// optionKind groups option values into the kinds that may not be mixed within
+// one option list. Integers and floats are both numbers.
+func optionKind(value interface{}) string {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Map, reflect.Struct:
+		return "map"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Invalid:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// ValidateOptions checks that all options of a select or multiselect field
+// have values of the same kind, so the form result has a predictable type.
+func ValidateOptions(field *Field) error {
+	if len(field.Options) == 0 {
+		return nil
+	}
+
+	firstKind := ""
+	for i, opt := range field.Options {
+		if opt == nil {
+			return errors.Errorf("field %s: option %d is empty", field.Key, i)
+		}
+		kind := optionKind(opt.Value)
+		if i == 0 {
+			firstKind = kind
+			continue
+		}
+		if kind != firstKind {
+			return errors.Errorf(
+				"field %s: option %q has a %s value but option %q has a %s value; all option values of a field must have the same type",
+				field.Key, opt.Label, kind, field.Options[0].Label, firstKind)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// OptionsFrom describes where a select or multiselect field loads its options
+// from at runtime. Exactly one of State, File or Command must be set.
+//
+// The source yields a list of items. Scalar items become options whose label
+// and value are the item itself. For map items, Label and Value are dotted
+// paths (e.g. `owner.login`) into each item, defaulting to `label` and
+// `value`.
+type OptionsFrom struct {
+	// State is the key of a list in the wizard state.
+	State string `yaml:"state,omitempty"`
+	// File is the path to a JSON, YAML or CSV file. A relative path is
+	// resolved against the directory of the form (see Form.Dir).
+	File string `yaml:"file,omitempty"`
+	// Command is a shell command whose stdout is parsed. It reads the state
+	// from UHOH_* environment variables (see StateEnv).
+	Command string `yaml:"command,omitempty"`
+	// Format is one of json, yaml, csv or lines. It defaults to the file
+	// extension for files and to lines for commands.
+	Format string `yaml:"format,omitempty"`
+	// Path is a dotted path to the list inside the loaded document.
+	Path  string `yaml:"path,omitempty"`
+	Label string `yaml:"label,omitempty"`
+	Value string `yaml:"value,omitempty"`
+}
+
+// ResolveOptions returns a copy of the form in which every field with an
+// options_from block has its options loaded, appended after the options
+// declared statically. The receiver is left untouched so the form can be
+// resolved again later against a different state.
+func (f *Form) ResolveOptions(ctx context.Context, state map[string]interface{}) (*Form, error) {
+	resolved := f.clone()
+	for _, group := range resolved.Groups {
+		for _, field := range group.Fields {
+			if field.OptionsFrom == nil {
+				continue
+			}
+			options, err := field.OptionsFrom.Resolve(ctx, f.Dir, state)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not load options for field %s", field.Key)
+			}
+			field.Options = append(append([]*Option{}, field.Options...), options...)
+			if err := ValidateOptions(field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// Resolve loads the options from the configured source. Relative file paths
+// are resolved against dir.
+func (o *OptionsFrom) Resolve(ctx context.Context, dir string, state map[string]interface{}) ([]*Option, error) {
+	var doc interface{}
+
+	switch {
+	case o.State != "":
+		v, ok := lookupPath(state, o.State)
+		if !ok {
+			return nil, errors.Errorf("state key %s is not set", o.State)
+		}
+		doc = v
+	case o.File != "":
+		path := o.File
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read options file %s", o.File)
+		}
+		format := o.Format
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(o.File)), ".")
+		}
+		doc, err = parseOptionsDocument(data, format)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse options file %s", o.File)
+		}
+	case o.Command != "":
+		// Not a login shell, so the options do not depend on the user's
+		// profile; the state is passed in the environment (see StateEnv)
+		cmd := exec.CommandContext(ctx, "bash", "-c", o.Command)
+		cmd.Env = append(os.Environ(), StateEnv(state)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, errors.Wrapf(err, "options command failed: %s", strings.TrimSpace(stderr.String()))
+		}
+		format := o.Format
+		if format == "" {
+			format = "lines"
+		}
+		doc, err = parseOptionsDocument(out, format)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse options command output")
+		}
+	default:
+		return nil, errors.New("options_from needs one of state, file or command")
+	}
+
+	if o.Path != "" {
+		v, ok := lookupPath(doc, o.Path)
+		if !ok {
+			return nil, errors.Errorf("path %s not found in options source", o.Path)
+		}
+		doc = v
+	}
+
+	items, ok := doc.([]interface{})
+	if !ok {
+		if doc == nil {
+			return []*Option{}, nil
+		}
+		if strs, isStrings := doc.([]string); isStrings {
+			items = make([]interface{}, len(strs))
+			for i, s := range strs {
+				items[i] = s
+			}
+		} else if maps, isMaps := doc.([]map[string]interface{}); isMaps {
+			items = make([]interface{}, len(maps))
+			for i, m := range maps {
+				items[i] = m
+			}
+		} else {
+			return nil, errors.Errorf("options source is not a list (got %T)", doc)
+		}
+	}
+
+	labelPath, valuePath := o.Label, o.Value
+	if labelPath == "" {
+		labelPath = "label"
+	}
+	if valuePath == "" {
+		valuePath = "value"
+	}
+
+	options := make([]*Option, 0, len(items))
+	for i, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, map[interface{}]interface{}:
+			value, ok := lookupPath(item, valuePath)
+			if !ok {
+				return nil, errors.Errorf("option %d has no value at %s", i, valuePath)
+			}
+			label, ok := lookupPath(item, labelPath)
+			if !ok {
+				label = value
+			}
+			options = append(options, &Option{Label: fmt.Sprintf("%v", label), Value: value})
+		default:
+			options = append(options, &Option{Label: fmt.Sprintf("%v", item), Value: item})
+		}
+	}
+
+	return options, nil
+}
+
+// parseOptionsDocument parses the raw bytes of an options source.
+func parseOptionsDocument(data []byte, format string) (interface{}, error) {
+	switch format {
+	case "json":
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	case "yaml", "yml":
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	case "csv":
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return []interface{}{}, nil
+		}
+		// The first row is the header; every other row becomes a map keyed by
+		// column name. Label and value default to the first column.
+		header := records[0]
+		items := make([]interface{}, 0, len(records)-1)
+		for _, record := range records[1:] {
+			item := map[string]interface{}{}
+			for i, col := range header {
+				if i < len(record) {
+					item[col] = record[i]
+				}
+			}
+			if _, ok := item["value"]; !ok && len(record) > 0 {
+				item["value"] = record[0]
+			}
+			if _, ok := item["label"]; !ok && len(record) > 0 {
+				item["label"] = record[0]
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case "lines", "":
+		items := []interface{}{}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				items = append(items, line)
+			}
+		}
+		return items, nil
+	default:
+		return nil, errors.Errorf("unsupported options format %s", format)
+	}
+}
+
+// lookupPath follows a dotted path through nested maps and lists. List
+// elements are addressed by their index.
+func lookupPath(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+	current := v
+	for _, part := range strings.Split(path, ".") {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			next, ok := c[part]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case map[interface{}]interface{}:
+			next, ok := c[part]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return nil, false
+			}
+			current = c[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
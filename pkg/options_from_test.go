@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptionsFromResolve(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"repos.json":  `{"items": [{"name": "uhoh", "owner": {"login": "go-go-golems"}}, {"name": "glazed", "owner": {"login": "go-go-golems"}}]}`,
+		"colors.yaml": "- red\n- green\n",
+		"plans.csv":   "value,label\nfree,Free plan\npro,Pro plan\n",
+		"sizes.txt":   "S\nM\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state := map[string]interface{}{
+		"names": []interface{}{"a", "b"},
+		"repos": []interface{}{
+			map[string]interface{}{"full_name": "go-go-golems/uhoh", "name": "uhoh"},
+		},
+		"repo_name": "it's uhoh",
+	}
+
+	tests := []struct {
+		name     string
+		from     OptionsFrom
+		expected []*Option
+	}{
+		{
+			name:     "state list of scalars",
+			from:     OptionsFrom{State: "names"},
+			expected: []*Option{{Label: "a", Value: "a"}, {Label: "b", Value: "b"}},
+		},
+		{
+			name:     "state list of maps",
+			from:     OptionsFrom{State: "repos", Label: "name", Value: "full_name"},
+			expected: []*Option{{Label: "uhoh", Value: "go-go-golems/uhoh"}},
+		},
+		{
+			name: "json file with path",
+			from: OptionsFrom{File: "repos.json", Path: "items", Label: "name", Value: "owner.login"},
+			expected: []*Option{
+				{Label: "uhoh", Value: "go-go-golems"},
+				{Label: "glazed", Value: "go-go-golems"},
+			},
+		},
+		{
+			name:     "yaml file",
+			from:     OptionsFrom{File: "colors.yaml"},
+			expected: []*Option{{Label: "red", Value: "red"}, {Label: "green", Value: "green"}},
+		},
+		{
+			name:     "csv file",
+			from:     OptionsFrom{File: "plans.csv"},
+			expected: []*Option{{Label: "Free plan", Value: "free"}, {Label: "Pro plan", Value: "pro"}},
+		},
+		{
+			name:     "file with explicit format",
+			from:     OptionsFrom{File: "sizes.txt", Format: "lines"},
+			expected: []*Option{{Label: "S", Value: "S"}, {Label: "M", Value: "M"}},
+		},
+		{
+			name:     "absolute file",
+			from:     OptionsFrom{File: filepath.Join(dir, "colors.yaml")},
+			expected: []*Option{{Label: "red", Value: "red"}, {Label: "green", Value: "green"}},
+		},
+		{
+			name:     "command lines",
+			from:     OptionsFrom{Command: `printf 'x\n\ny\n'`},
+			expected: []*Option{{Label: "x", Value: "x"}, {Label: "y", Value: "y"}},
+		},
+		{
+			name:     "command reads the state from the environment",
+			from:     OptionsFrom{Command: `echo "$UHOH_REPO_NAME"`},
+			expected: []*Option{{Label: "it's uhoh", Value: "it's uhoh"}},
+		},
+		{
+			name:     "command json",
+			from:     OptionsFrom{Command: `echo '[{"label": "One", "value": 1}]'`, Format: "json"},
+			expected: []*Option{{Label: "One", Value: float64(1)}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.from.Resolve(context.Background(), dir, state)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestOptionsFromResolveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    OptionsFrom
+		problem string
+	}{
+		{"no source", OptionsFrom{}, "needs one of"},
+		{"missing state key", OptionsFrom{State: "nope"}, "is not set"},
+		{"not a list", OptionsFrom{State: "name"}, "not a list"},
+		{"missing file", OptionsFrom{File: "nope.json"}, "could not read options file"},
+		{"failing command", OptionsFrom{Command: "echo oops >&2; exit 1"}, "oops"},
+		{"unknown format", OptionsFrom{Command: "echo a", Format: "xml"}, "unsupported options format"},
+		{"missing path", OptionsFrom{State: "names", Path: "items"}, "path items not found"},
+		{"map without value", OptionsFrom{State: "repos"}, "has no value at value"},
+	}
+	state := map[string]interface{}{
+		"name":  "uhoh",
+		"names": []interface{}{"a"},
+		"repos": []interface{}{map[string]interface{}{"name": "uhoh"}},
+	}
+	for _, tt := range tests {
+		_, err := tt.from.Resolve(context.Background(), t.TempDir(), state)
+		if err == nil || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+}
+
+func TestFormResolveOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "more.yaml"), []byte("- c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	field := &Field{
+		Type:        "select",
+		Key:         "letter",
+		Options:     []*Option{{Label: "A", Value: "a"}},
+		OptionsFrom: &OptionsFrom{File: "more.yaml"},
+	}
+	form := &Form{Dir: dir, Groups: []*Group{{Fields: []*Field{field}}}}
+
+	resolved, err := form.ResolveOptions(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*Option{{Label: "A", Value: "a"}, {Label: "c", Value: "c"}}
+	if got := resolved.Groups[0].Fields[0].Options; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if len(field.Options) != 1 {
+		t.Errorf("expected the form to be left alone, got %v", field.Options)
+	}
+
+	// Relative to the form, not to the working directory
+	form.Dir = ""
+	if _, err := form.ResolveOptions(context.Background(), nil); err == nil {
+		t.Error("expected an error resolving the file against the working directory")
+	}
+}
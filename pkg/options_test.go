@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptionIndex(t *testing.T) {
+	options := []*Option{
+		{Label: "One", Value: 1},
+		{Label: "Two", Value: 2.5},
+		{Label: "Yes", Value: true},
+		{Label: "Map", Value: map[string]interface{}{"id": 7}},
+		{Label: "Text", Value: "text"},
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected int
+	}{
+		{"nil", nil, -1},
+		{"int", 1, 0},
+		{"float", 2.5, 1},
+		{"bool", true, 2},
+		{"map", map[string]interface{}{"id": 7}, 3},
+		{"string", "text", 4},
+		{"string of a number", "1", 0},
+		{"int64", int64(1), 0},
+		{"string of a bool", "true", 2},
+		{"no match", "three", -1},
+	}
+	for _, tt := range tests {
+		if got := optionIndex(options, tt.value); got != tt.expected {
+			t.Errorf("%s: expected index %d, got %d", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []*Option
+		problem string
+	}{
+		{"no options", nil, ""},
+		{"strings", []*Option{{Label: "A", Value: "a"}, {Label: "B", Value: "b"}}, ""},
+		{"ints and floats", []*Option{{Label: "A", Value: 1}, {Label: "B", Value: 2.5}}, ""},
+		{"maps", []*Option{{Label: "A", Value: map[string]interface{}{}}, {Label: "B", Value: map[string]interface{}{"x": 1}}}, ""},
+		{"string and int", []*Option{{Label: "A", Value: "a"}, {Label: "B", Value: 2}}, `option "B" has a number value but option "A" has a string value`},
+		{"bool and string", []*Option{{Label: "A", Value: true}, {Label: "B", Value: "b"}}, "string value"},
+		{"empty option", []*Option{{Label: "A", Value: "a"}, nil}, "option 1 is empty"},
+	}
+	for _, tt := range tests {
+		err := ValidateOptions(&Field{Key: "choice", Options: tt.options})
+		if tt.problem == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+}
+
+func TestSelectValues(t *testing.T) {
+	numbers := []*Option{{Label: "One", Value: 1}, {Label: "Two", Value: 2}, {Label: "Three", Value: 3}}
+	strs := []*Option{{Label: "A", Value: "a"}, {Label: "B", Value: "b"}}
+
+	sv := newSelectValue(&Field{Options: numbers, Value: "2"})
+	if *sv.index != 1 || sv.value() != 2 {
+		t.Errorf("expected the default to select 2, got index %d and value %#v", *sv.index, sv.value())
+	}
+	if sv := newSelectValue(&Field{Options: numbers}); sv.value() != nil {
+		t.Errorf("expected no value without a default, got %#v", sv.value())
+	}
+
+	tests := []struct {
+		name     string
+		field    *Field
+		expected interface{}
+	}{
+		{"numbers", &Field{Options: numbers, Value: []interface{}{3, 1}}, []interface{}{3, 1}},
+		{"strings", &Field{Options: strs, Value: []string{"b"}}, []string{"b"}},
+		{"no default", &Field{Options: strs}, []string{}},
+		{"unknown default", &Field{Options: numbers, Value: []interface{}{4}}, []interface{}{}},
+		{"default that is not a list", &Field{Key: "m", Options: strs, Value: "a"}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := newMultiSelectValue(tt.field).value(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.name, tt.expected, got)
+		}
+	}
+}
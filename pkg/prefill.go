@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// clone returns a copy of the form with copied groups and fields, so the copy
+// can be adjusted for one run without touching the definition.
+func (f *Form) clone() *Form {
+	ret := *f
+	ret.Groups = make([]*Group, len(f.Groups))
+	for i, group := range f.Groups {
+		g := *group
+		g.Fields = make([]*Field, len(group.Fields))
+		for j, field := range group.Fields {
+			fieldCopy := *field
+			g.Fields[j] = &fieldCopy
+		}
+		ret.Groups[i] = &g
+	}
+	return &ret
+}
+
+// Prefill returns a copy of the form in which every field whose key is set in
+// state starts with that value instead of its YAML default. Values are coerced
+// to what the field type expects; a value that cannot be coerced leaves the
+// default in place. Fields with `prefill: false` are always asked afresh.
+func (f *Form) Prefill(state map[string]interface{}) *Form {
+	ret := f.clone()
+	if len(state) == 0 {
+		return ret
+	}
+
+	for _, group := range ret.Groups {
+		for _, field := range group.Fields {
+			if field.Key == "" || (field.Prefill != nil && !*field.Prefill) {
+				continue
+			}
+			v, ok := state[field.Key]
+			if !ok || v == nil {
+				continue
+			}
+			coerced, err := coerceFieldValue(field.Type, v)
+			if err != nil {
+				log.Warn().Err(err).Str("field", field.Key).Msg("Not prefilling field")
+				continue
+			}
+			field.Value = coerced
+		}
+	}
+	return ret
+}
+
+// coerceFieldValue converts a state value into a default value for a field of
+// the given type.
+func coerceFieldValue(fieldType string, v interface{}) (interface{}, error) {
+	switch fieldType {
+	case "input", "text", "filepicker":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprintf("%v", v), nil
+
+	case "confirm":
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+			if err != nil {
+				switch strings.ToLower(strings.TrimSpace(b)) {
+				case "yes", "y", "on":
+					return true, nil
+				case "no", "n", "off":
+					return false, nil
+				}
+				return nil, fmt.Errorf("cannot use %q as a boolean", b)
+			}
+			return parsed, nil
+		default:
+			return nil, fmt.Errorf("cannot use %T as a boolean", v)
+		}
+
+	case "multiselect":
+		if s, ok := v.(string); ok {
+			parts := []interface{}{}
+			for _, part := range strings.Split(s, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					parts = append(parts, part)
+				}
+			}
+			return parts, nil
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			ret := make([]interface{}, rv.Len())
+			for i := range ret {
+				ret[i] = rv.Index(i).Interface()
+			}
+			return ret, nil
+		}
+		return []interface{}{v}, nil
+
+	case "note":
+		return nil, fmt.Errorf("note fields have no value")
+
+	default:
+		// select, number, integer, date and datetime match or parse the value
+		// themselves.
+		return v, nil
+	}
+}
@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoerceFieldValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		value     interface{}
+		expected  interface{}
+		fails     bool
+	}{
+		{"input string", "input", "hello", "hello", false},
+		{"input number", "input", 42, "42", false},
+		{"text bool", "text", true, "true", false},
+		{"confirm bool", "confirm", false, false, false},
+		{"confirm string", "confirm", "true", true, false},
+		{"confirm yes", "confirm", " Yes ", true, false},
+		{"confirm off", "confirm", "off", false, false},
+		{"confirm nonsense", "confirm", "maybe", nil, true},
+		{"confirm number", "confirm", 1, nil, true},
+		{"multiselect string", "multiselect", "a, b,,c", []interface{}{"a", "b", "c"}, false},
+		{"multiselect slice", "multiselect", []string{"a", "b"}, []interface{}{"a", "b"}, false},
+		{"multiselect scalar", "multiselect", 3, []interface{}{3}, false},
+		{"note", "note", "x", nil, true},
+		{"select", "select", 2, 2, false},
+		{"integer", "integer", int64(5), int64(5), false},
+	}
+	for _, tt := range tests {
+		got, err := coerceFieldValue(tt.fieldType, tt.value)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %#v", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestPrefill(t *testing.T) {
+	no := false
+	form := &Form{Groups: []*Group{{Fields: []*Field{
+		{Type: "input", Key: "name", Value: "default"},
+		{Type: "confirm", Key: "ok", Value: true},
+		{Type: "input", Key: "secret", Value: "keep", Prefill: &no},
+		{Type: "input", Key: "missing", Value: "keep"},
+		{Type: "input", Key: "empty", Value: "keep"},
+	}}}}
+
+	filled := form.Prefill(map[string]interface{}{
+		"name":   "octocat",
+		"ok":     "maybe",
+		"secret": "leaked",
+		"empty":  nil,
+	})
+
+	expected := []interface{}{"octocat", true, "keep", "keep", "keep"}
+	for i, field := range filled.Groups[0].Fields {
+		if !reflect.DeepEqual(field.Value, expected[i]) {
+			t.Errorf("%s: expected %#v, got %#v", field.Key, expected[i], field.Value)
+		}
+	}
+	if form.Groups[0].Fields[0].Value != "default" {
+		t.Errorf("expected the form definition to be left alone, got %#v", form.Groups[0].Fields[0].Value)
+	}
+}
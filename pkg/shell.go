@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateEnvPrefix starts the names of the environment variables StateEnv
+// passes the state in.
+const StateEnvPrefix = "UHOH_"
+
+// StateEnv returns the top-level keys of state as KEY=value environment
+// entries, so shell commands can read answers as quoted variables
+// ("$UHOH_REPO_NAME") instead of having them pasted into the command text.
+// Names are the key in upper case with other characters than letters, digits
+// and underscores replaced by underscores. Lists and maps are written as
+// JSON, times in RFC 3339 and unset values as an empty string.
+func StateEnv(state map[string]interface{}) []string {
+	env := make([]string, 0, len(state))
+	for key, value := range state {
+		env = append(env, StateEnvName(key)+"="+envValue(value))
+	}
+	sort.Strings(env)
+	return env
+}
+
+// ShellQuote quotes s as a single shell word: it is wrapped in single quotes,
+// with single quotes inside it escaped.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellQuoteValue is the `shellquote` template function: it quotes any
+// state value, written as StateEnv writes it.
+func shellQuoteValue(v interface{}) string {
+	return ShellQuote(envValue(v))
+}
+
+// StateEnvName returns the name of the environment variable StateEnv sets
+// for key.
+func StateEnvName(key string) string {
+	name := []rune(strings.ToUpper(key))
+	for i, r := range name {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '_' {
+			name[i] = '_'
+		}
+	}
+	return StateEnvPrefix + string(name)
+}
+
+func envValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []interface{}, []string, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStateEnv(t *testing.T) {
+	state := map[string]interface{}{
+		"repo_name": "it's; rm -rf ~",
+		"count":     3,
+		"ok":        true,
+		"unset":     nil,
+		"tags":      []string{"a", "b"},
+		"owner":     map[string]interface{}{"login": "octocat"},
+		"due":       time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		"user.name": "x",
+	}
+	expected := []string{
+		"UHOH_COUNT=3",
+		"UHOH_DUE=2024-03-01T12:00:00Z",
+		"UHOH_OK=true",
+		`UHOH_OWNER={"login":"octocat"}`,
+		"UHOH_REPO_NAME=it's; rm -rf ~",
+		`UHOH_TAGS=["a","b"]`,
+		"UHOH_UNSET=",
+		"UHOH_USER_NAME=x",
+	}
+	if got := StateEnv(state); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"plain", "'plain'"},
+		{"", "''"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf ~); `x`", "'$(rm -rf ~); `x`'"},
+		{3, "'3'"},
+		{nil, "''"},
+		{[]string{"a b"}, `'["a b"]'`},
+	}
+	for _, tt := range tests {
+		if got := shellQuoteValue(tt.value); got != tt.expected {
+			t.Errorf("%#v: expected %s, got %s", tt.value, tt.expected, got)
+		}
+	}
+
+	out, err := Interpolate("echo {{ .name | shellquote }}", map[string]interface{}{"name": "it's"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `echo 'it'\''s'`; out != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
@@ -0,0 +1,245 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultDateFormat     = "2006-01-02"
+	defaultDateTimeFormat = "2006-01-02 15:04"
+)
+
+// isTypedField reports whether the field type is entered as text but returned
+// as a typed value (int64, float64 or time.Time).
+func isTypedField(fieldType string) bool {
+	switch fieldType {
+	case "number", "integer", "date", "datetime":
+		return true
+	default:
+		return false
+	}
+}
+
+// typedValue binds the text of an input to a field whose result is not a
+// string. The text is parsed (and range-checked) whenever the value is read.
+type typedValue struct {
+	text  *string
+	parse func(string) (interface{}, error)
+	allow func(rune) bool
+}
+
+// value parses the current text. Blank text yields nil, which required and
+// optional fields treat as "not answered".
+func (tv *typedValue) value() (interface{}, error) {
+	return tv.parseText(*tv.text)
+}
+
+func (tv *typedValue) parseText(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	return tv.parse(s)
+}
+
+// newTypedValue creates the binding for a number, integer, date or datetime
+// field, initialized from the field's default value.
+func newTypedValue(field *Field) (*typedValue, error) {
+	text := ""
+	tv := &typedValue{text: &text}
+
+	switch field.Type {
+	case "integer", "number":
+		attrs := field.NumberAttributes
+		if attrs == nil {
+			attrs = &NumberAttributes{}
+		}
+		if field.Type == "integer" {
+			tv.parse = func(s string) (interface{}, error) {
+				i, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return nil, errors.Errorf("%q is not a whole number", s)
+				}
+				if err := attrs.check(float64(i)); err != nil {
+					return nil, err
+				}
+				return i, nil
+			}
+			tv.allow = func(r rune) bool {
+				return (r >= '0' && r <= '9') || r == '-' || r == '+'
+			}
+		} else {
+			tv.parse = func(s string) (interface{}, error) {
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, errors.Errorf("%q is not a number", s)
+				}
+				if err := attrs.check(f); err != nil {
+					return nil, err
+				}
+				return f, nil
+			}
+			tv.allow = func(r rune) bool {
+				return (r >= '0' && r <= '9') || strings.ContainsRune("-+.eE", r)
+			}
+		}
+		if field.Value != nil {
+			text = fmt.Sprintf("%v", field.Value)
+		}
+
+	case "date", "datetime":
+		attrs := field.DateAttributes
+		if attrs == nil {
+			attrs = &DateAttributes{}
+		}
+		format := attrs.Format
+		if format == "" {
+			format = defaultDateFormat
+			if field.Type == "datetime" {
+				format = defaultDateTimeFormat
+			}
+		}
+		var minTime, maxTime time.Time
+		var err error
+		if attrs.Min != "" {
+			if minTime, err = time.ParseInLocation(format, attrs.Min, time.Local); err != nil {
+				return nil, errors.Wrapf(err, "invalid min date for field %s", field.Key)
+			}
+		}
+		if attrs.Max != "" {
+			if maxTime, err = time.ParseInLocation(format, attrs.Max, time.Local); err != nil {
+				return nil, errors.Wrapf(err, "invalid max date for field %s", field.Key)
+			}
+		}
+		tv.parse = func(s string) (interface{}, error) {
+			t, err := time.ParseInLocation(format, s, time.Local)
+			if err != nil {
+				return nil, errors.Errorf("%q does not match the format %s", s, format)
+			}
+			if !minTime.IsZero() && t.Before(minTime) {
+				return nil, errors.Errorf("must not be before %s", attrs.Min)
+			}
+			if !maxTime.IsZero() && t.After(maxTime) {
+				return nil, errors.Errorf("must not be after %s", attrs.Max)
+			}
+			return t, nil
+		}
+		if attrs.Format == "" {
+			// Custom formats may spell out months, weekdays or zones, so only
+			// the numeric default layouts filter what can be typed
+			tv.allow = func(r rune) bool {
+				return (r >= '0' && r <= '9') || strings.ContainsRune("-: ", r)
+			}
+		}
+		switch v := field.Value.(type) {
+		case nil:
+		case time.Time:
+			text = v.Format(format)
+		default:
+			text = fmt.Sprintf("%v", v)
+		}
+
+	default:
+		return nil, errors.Errorf("field type %s is not a typed field", field.Type)
+	}
+
+	return tv, nil
+}
+
+// check enforces min, max and step on a numeric value.
+func (na *NumberAttributes) check(f float64) error {
+	if na.Min != nil && f < *na.Min {
+		return errors.Errorf("must be at least %v", *na.Min)
+	}
+	if na.Max != nil && f > *na.Max {
+		return errors.Errorf("must be at most %v", *na.Max)
+	}
+	if na.Step > 0 {
+		base := 0.0
+		if na.Min != nil {
+			base = *na.Min
+		}
+		steps := (f - base) / na.Step
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			return errors.Errorf("must be in steps of %v", na.Step)
+		}
+	}
+	return nil
+}
+
+// newTypedInput creates the huh input for a typed field.
+func newTypedInput(field *Field, tv *typedValue) huh.Field {
+	input := huh.NewInput().
+		Title(field.Title).
+		Value(tv.text)
+
+	var prompt, placeholder string
+	charLimit := 0
+	if field.NumberAttributes != nil {
+		prompt = field.NumberAttributes.Prompt
+		placeholder = field.NumberAttributes.Placeholder
+		charLimit = field.NumberAttributes.CharLimit
+	}
+	if field.DateAttributes != nil {
+		prompt = field.DateAttributes.Prompt
+		placeholder = field.DateAttributes.Placeholder
+	}
+	if placeholder == "" {
+		switch field.Type {
+		case "date":
+			placeholder = defaultDateFormat
+		case "datetime":
+			placeholder = defaultDateTimeFormat
+		}
+		if field.DateAttributes != nil && field.DateAttributes.Format != "" {
+			placeholder = field.DateAttributes.Format
+		}
+	}
+	if prompt != "" {
+		input = input.Prompt(prompt)
+	}
+	if placeholder != "" {
+		input = input.Placeholder(placeholder)
+	}
+	if charLimit > 0 {
+		input = input.CharLimit(charLimit)
+	}
+
+	return &filteredInput{Input: input, allow: tv.allow}
+}
+
+// filteredInput is a huh.Input that drops typed characters rejected by allow,
+// so a number field never contains letters in the first place.
+type filteredInput struct {
+	*huh.Input
+	allow func(rune) bool
+}
+
+var _ huh.Field = &filteredInput{}
+
+func (fi *filteredInput) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyRunes && fi.allow != nil {
+		runes := make([]rune, 0, len(keyMsg.Runes))
+		for _, r := range keyMsg.Runes {
+			if fi.allow(r) {
+				runes = append(runes, r)
+			}
+		}
+		if len(runes) == 0 {
+			return fi, nil
+		}
+		keyMsg.Runes = runes
+		msg = keyMsg
+	}
+
+	_, cmd := fi.Input.Update(msg)
+	return fi, cmd
+}
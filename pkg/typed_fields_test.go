@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestTypedValueParse(t *testing.T) {
+	date := func(s string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		field    *Field
+		text     string
+		expected interface{}
+		problem  string
+	}{
+		{"blank", &Field{Type: "integer"}, "  ", nil, ""},
+		{"integer", &Field{Type: "integer"}, "42", int64(42), ""},
+		{"negative integer", &Field{Type: "integer"}, "-7", int64(-7), ""},
+		{"integer with decimals", &Field{Type: "integer"}, "4.2", nil, "not a whole number"},
+		{"integer below min", &Field{Type: "integer", NumberAttributes: &NumberAttributes{Min: float(1)}}, "0", nil, "must be at least 1"},
+		{"integer at min", &Field{Type: "integer", NumberAttributes: &NumberAttributes{Min: float(1)}}, "1", int64(1), ""},
+		{"integer above max", &Field{Type: "integer", NumberAttributes: &NumberAttributes{Max: float(10)}}, "11", nil, "must be at most 10"},
+		{"integer at max", &Field{Type: "integer", NumberAttributes: &NumberAttributes{Max: float(10)}}, "10", int64(10), ""},
+		{"integer off step", &Field{Type: "integer", NumberAttributes: &NumberAttributes{Min: float(1), Step: 2}}, "4", nil, "in steps of 2"},
+		{"integer on step from min", &Field{Type: "integer", NumberAttributes: &NumberAttributes{Min: float(1), Step: 2}}, "5", int64(5), ""},
+		{"number", &Field{Type: "number"}, "2.5", 2.5, ""},
+		{"number with exponent", &Field{Type: "number"}, "1e3", 1000.0, ""},
+		{"not a number", &Field{Type: "number"}, "abc", nil, "not a number"},
+		{"number on decimal step", &Field{Type: "number", NumberAttributes: &NumberAttributes{Step: 0.1}}, "0.3", 0.3, ""},
+		{"number off decimal step", &Field{Type: "number", NumberAttributes: &NumberAttributes{Step: 0.1}}, "0.35", nil, "in steps of 0.1"},
+		{"date", &Field{Type: "date"}, "2024-02-29", date("2024-02-29 00:00"), ""},
+		{"invalid date", &Field{Type: "date"}, "2023-02-29", nil, "does not match the format"},
+		{"datetime", &Field{Type: "datetime"}, "2024-03-01 13:30", date("2024-03-01 13:30"), ""},
+		{"date with custom format", &Field{Type: "date", DateAttributes: &DateAttributes{Format: "02 Jan 2006"}}, "05 Mar 2024", date("2024-03-05 00:00"), ""},
+		{"date before min", &Field{Type: "date", DateAttributes: &DateAttributes{Min: "2024-01-01"}}, "2023-12-31", nil, "must not be before 2024-01-01"},
+		{"date at min", &Field{Type: "date", DateAttributes: &DateAttributes{Min: "2024-01-01"}}, "2024-01-01", date("2024-01-01 00:00"), ""},
+		{"date after max", &Field{Type: "date", DateAttributes: &DateAttributes{Max: "2024-12-31"}}, "2025-01-01", nil, "must not be after 2024-12-31"},
+	}
+	for _, tt := range tests {
+		tv, err := newTypedValue(tt.field)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		got, err := tv.parseText(tt.text)
+		if tt.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("%s: expected an error containing %q, got %v (%v)", tt.name, tt.problem, err, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestTypedValueDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    *Field
+		expected string
+	}{
+		{"integer", &Field{Type: "integer", Value: 3}, "3"},
+		{"number", &Field{Type: "number", Value: 2.5}, "2.5"},
+		{"date string", &Field{Type: "date", Value: "2024-01-02"}, "2024-01-02"},
+		{"date time", &Field{Type: "date", Value: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)}, "2024-01-02"},
+		{"custom format", &Field{
+			Type:           "date",
+			Value:          time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+			DateAttributes: &DateAttributes{Format: "02/01/2006"},
+		}, "02/01/2024"},
+		{"no default", &Field{Type: "datetime"}, ""},
+	}
+	for _, tt := range tests {
+		tv, err := newTypedValue(tt.field)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if *tv.text != tt.expected {
+			t.Errorf("%s: expected the text %q, got %q", tt.name, tt.expected, *tv.text)
+		}
+	}
+}
+
+func TestTypedValueInvalidBounds(t *testing.T) {
+	for _, attrs := range []*DateAttributes{{Min: "yesterday"}, {Max: "2024/01/01"}} {
+		if _, err := newTypedValue(&Field{Type: "date", Key: "when", DateAttributes: attrs}); err == nil {
+			t.Errorf("%+v: expected an invalid bound error", attrs)
+		}
+	}
+	if _, err := newTypedValue(&Field{Type: "input"}); err == nil {
+		t.Error("expected an error for a field that is not typed")
+	}
+}
+
+func TestTypedValueAllow(t *testing.T) {
+	tests := []struct {
+		field   *Field
+		allowed string
+		refused string
+	}{
+		{&Field{Type: "integer"}, "0123456789-+", ".eab "},
+		{&Field{Type: "number"}, "0123456789-+.eE", "ab ,"},
+		{&Field{Type: "date"}, "0123456789-", "abJ/"},
+		{&Field{Type: "datetime"}, "0123456789-: ", "abT"},
+	}
+	for _, tt := range tests {
+		tv, err := newTypedValue(tt.field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range tt.allowed {
+			if !tv.allow(r) {
+				t.Errorf("%s: expected %q to be allowed", tt.field.Type, r)
+			}
+		}
+		for _, r := range tt.refused {
+			if tv.allow(r) {
+				t.Errorf("%s: expected %q to be refused", tt.field.Type, r)
+			}
+		}
+	}
+
+	// Custom formats may spell out month names
+	tv, err := newTypedValue(&Field{Type: "date", DateAttributes: &DateAttributes{Format: "Jan 2, 2006"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tv.allow != nil {
+		t.Error("expected no filter for a custom date format")
+	}
+}
@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/pkg/errors"
+)
+
+// compiledValidation pairs a Validation with its compiled expr program.
+type compiledValidation struct {
+	validation *Validation
+	program    *vm.Program
+}
+
+// fieldValidator enforces `required` and evaluates the validations of a single
+// field against the in-progress values of the whole form.
+//
+// An empty value fails a required field before any validation runs, and skips
+// the validations of an optional field, so conditions like
+// `parseInt(value) <= 0` only ever see values the user actually entered.
+//
+// A validation condition describes the invalid state: when it evaluates to
+// true, the validation's error message is reported. The condition can refer to
+// the field's current value as `value`, to every other field of the form by
+// its key, and to the same map under `state`.
+type fieldValidator struct {
+	name        string
+	required    bool
+	typed       *typedValue
+	validations []compiledValidation
+	values      map[string]interface{}
+}
+
+// exprFunctions are the helper functions available in validation conditions.
+var exprFunctions = []expr.Option{
+	expr.Function("parseInt", func(params ...interface{}) (interface{}, error) {
+		s := strings.TrimSpace(fmt.Sprintf("%v", params[0]))
+		return strconv.ParseInt(s, 10, 64)
+	}, new(func(interface{}) int64)),
+	expr.Function("parseFloat", func(params ...interface{}) (interface{}, error) {
+		s := strings.TrimSpace(fmt.Sprintf("%v", params[0]))
+		return strconv.ParseFloat(s, 64)
+	}, new(func(interface{}) float64)),
+}
+
+// newFieldValidator compiles the validations of field. values is the map of
+// pointers built by BuildBubbleTeaModel, read lazily each time the field is
+// validated.
+func newFieldValidator(field *Field, values map[string]interface{}) (*fieldValidator, error) {
+	name := field.Title
+	if name == "" {
+		name = field.Key
+	}
+	fv := &fieldValidator{
+		name:     name,
+		required: field.Required,
+		values:   values,
+	}
+	if tv, ok := values[field.Key].(*typedValue); ok {
+		fv.typed = tv
+	}
+
+	for _, v := range field.Validation {
+		if v == nil || v.Condition == "" {
+			continue
+		}
+		program, err := compileCondition(v.Condition)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compile validation condition for field %s: %s", field.Key, v.Condition)
+		}
+		fv.validations = append(fv.validations, compiledValidation{
+			validation: v,
+			program:    program,
+		})
+	}
+
+	return fv, nil
+}
+
+// validate runs every validation against value and returns the error of the
+// first one whose condition holds. The text of typed fields is parsed first,
+// so `value` is an int64, float64 or time.Time in their conditions. A
+// condition that fails to evaluate (for example parseInt on a non-numeric
+// string) is reported the same way.
+func (fv *fieldValidator) validate(value interface{}) error {
+	if s, ok := value.(string); ok && fv.typed != nil {
+		parsed, err := fv.typed.parseText(s)
+		if err != nil {
+			return err
+		}
+		value = parsed
+	}
+	if isEmptyValue(value) {
+		if fv.required {
+			return errors.Errorf("%s is required", fv.name)
+		}
+		return nil
+	}
+	if len(fv.validations) == 0 {
+		return nil
+	}
+
+	env, err := conditionEnv(fv.values)
+	if err != nil {
+		return err
+	}
+	env["value"] = value
+
+	for _, cv := range fv.validations {
+		result, err := expr.Run(cv.program, env)
+		if err != nil {
+			return validationError(cv.validation)
+		}
+		if invalid, ok := result.(bool); ok && invalid {
+			return validationError(cv.validation)
+		}
+	}
+
+	return nil
+}
+
+// isEmptyValue reports whether value counts as "not answered": nil, a blank
+// string (unselected select, unpicked file) or an empty multiselect. Booleans are
+// always answered; a required confirm is checked separately.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []string:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func validationError(v *Validation) error {
+	if v.Error != "" {
+		return errors.New(v.Error)
+	}
+	return errors.Errorf("validation failed: %s", v.Condition)
+}
+
+// addValidation hooks validate into the huh field's Validate method. Select and
+// multiselect indices are translated back into option values first. Fields
+// that cannot be validated (notes) are returned unchanged.
+func addValidation(field huh.Field, options []*Option, validate func(interface{}) error) huh.Field {
+	switch f := field.(type) {
+	case *huh.Input:
+		return f.Validate(func(s string) error { return validate(s) })
+	case *filteredInput:
+		f.Input = f.Input.Validate(func(s string) error { return validate(s) })
+		return f
+	case *huh.Text:
+		return f.Validate(func(s string) error { return validate(s) })
+	case *huh.Select[int]:
+		return f.Validate(func(i int) error { return validate(optionValueAt(options, i)) })
+	case *huh.MultiSelect[int]:
+		return f.Validate(func(i []int) error { return validate(optionValuesAt(options, i)) })
+	case *huh.Confirm:
+		return f.Validate(func(b bool) error { return validate(b) })
+	case *huh.FilePicker:
+		return f.Validate(func(s string) error { return validate(s) })
+	default:
+		return field
+	}
+}
@@ -0,0 +1,38 @@
+package wizard
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// validateAnswers checks that the scripted answers are keyed by step IDs of
+// this wizard and that the answers of each step are a map.
+func (w *Wizard) validateAnswers() error {
+	var unknown []string
+	for stepID, stepAnswers := range w.answers {
+		if w.stepIndex(stepID) == -1 {
+			unknown = append(unknown, stepID)
+			continue
+		}
+		if _, ok := stepAnswers.(map[string]interface{}); !ok && stepAnswers != nil {
+			return errors.Errorf("answers for step %s must be a map of field keys to answers, got %T", stepID, stepAnswers)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.Errorf("answers given for unknown steps: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// stepAnswers returns the scripted answers of a step. A step without answers
+// gets an empty map, so it still runs headless and its fields keep their
+// defaults.
+func (w *Wizard) stepAnswers(stepID string) map[string]interface{} {
+	if answers, ok := w.answers[stepID].(map[string]interface{}); ok {
+		return answers
+	}
+	return map[string]interface{}{}
+}
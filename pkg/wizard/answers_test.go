@@ -0,0 +1,134 @@
+package wizard
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const headlessWizard = `
+name: Signup
+steps:
+  - id: details
+    type: form
+    form:
+      groups:
+        - fields:
+            - {type: input, key: name, required: true}
+            - {type: input, key: team, value: core}
+  - id: pick
+    type: decision
+    target_key: plan
+    choices: [free, pro]
+    next_step_map:
+      free: thanks_free
+      pro: thanks_pro
+  - id: thanks_free
+    type: action
+    action_type: function
+    function_name: visit
+    arguments: {id: thanks_free}
+    show_progress: false
+    show_completion: false
+    next_step: end
+  - id: thanks_pro
+    type: action
+    action_type: function
+    function_name: visit
+    arguments: {id: thanks_pro}
+    show_progress: false
+    show_completion: false
+`
+
+func TestRunScriptedAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		answers  map[string]interface{}
+		expected []string
+		state    map[string]interface{}
+		problem  string
+	}{
+		{
+			name: "free plan",
+			answers: map[string]interface{}{
+				"details": map[string]interface{}{"name": "Ada"},
+				"pick":    map[string]interface{}{"plan": "free"},
+			},
+			expected: []string{"thanks_free"},
+			state:    map[string]interface{}{"name": "Ada", "team": "core", "plan": "free"},
+		},
+		{
+			name: "pro plan",
+			answers: map[string]interface{}{
+				"details": map[string]interface{}{"name": "Ada", "team": "infra"},
+				"pick":    map[string]interface{}{"plan": "pro"},
+			},
+			expected: []string{"thanks_pro"},
+			state:    map[string]interface{}{"name": "Ada", "team": "infra", "plan": "pro"},
+		},
+		{
+			name: "missing form answer",
+			answers: map[string]interface{}{
+				"pick": map[string]interface{}{"plan": "pro"},
+			},
+			problem: "missing answer for required field name",
+		},
+		{
+			name: "missing decision answer",
+			answers: map[string]interface{}{
+				"details": map[string]interface{}{"name": "Ada"},
+			},
+			problem: "missing answer for plan: expected one of free, pro",
+		},
+		{
+			name: "invalid decision answer",
+			answers: map[string]interface{}{
+				"details": map[string]interface{}{"name": "Ada"},
+				"pick":    map[string]interface{}{"plan": "gold"},
+			},
+			problem: "invalid answer for plan: gold is not one of free, pro",
+		},
+		{
+			name:    "unknown step",
+			answers: map[string]interface{}{"extra": map[string]interface{}{}},
+			problem: "answers given for unknown steps: extra",
+		},
+		{
+			name:    "answers that are not a map",
+			answers: map[string]interface{}{"details": "Ada"},
+			problem: "answers for step details must be a map",
+		},
+	}
+	for _, tt := range tests {
+		visited := []string{}
+		w, err := LoadWizard(writeWizard(t, headlessWizard),
+			WithScriptedAnswers(tt.answers),
+			WithActionCallback("visit", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+				visited = append(visited, args["id"].(string))
+				return nil, nil
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := w.Run(context.Background(), nil)
+		if tt.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(visited, tt.expected) {
+			t.Errorf("%s: expected the steps %v to run, got %v", tt.name, tt.expected, visited)
+		}
+		for k, v := range tt.state {
+			if state[k] != v {
+				t.Errorf("%s: expected %s to be %v, got %v", tt.name, k, v, state[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package wizard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Checkpoint is the progress of a wizard run, saved as JSON after every step
+// so the run can be resumed later. See WithCheckpointFile and WithResume.
+type Checkpoint struct {
+	WizardName string `json:"wizard_name"`
+	// WizardHash is the SHA-256 of the wizard file the run was started from.
+	WizardHash string `json:"wizard_hash,omitempty"`
+	// Steps records the ID and type of every step of that wizard, so a changed
+	// definition can be checked for compatibility.
+	Steps []CheckpointStep `json:"steps"`
+	// CurrentStepID is the step to run next, or `end` if the run finished.
+	CurrentStepID string            `json:"current_step_id"`
+	History       []CheckpointEntry `json:"history"`
+	State         CheckpointState   `json:"state"`
+	SavedAt       time.Time         `json:"saved_at"`
+}
+
+// CheckpointStep identifies a step of the wizard a checkpoint was saved from.
+type CheckpointStep struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// CheckpointEntry is an executed step, as kept in the runner's history for
+// going back.
+type CheckpointEntry struct {
+	StepID      string          `json:"step_id"`
+	StateBefore CheckpointState `json:"state_before"`
+	Result      CheckpointState `json:"result"`
+}
+
+// WithCheckpointFile makes Run save a checkpoint to path after every step.
+// The file is removed when the wizard finishes.
+func WithCheckpointFile(path string) WizardOption {
+	return func(w *Wizard) {
+		w.checkpointFile = path
+	}
+}
+
+// WithResume makes Run continue from checkpoint instead of starting at the
+// first step. The initial state is ignored; the checkpoint's state is used.
+func WithResume(checkpoint *Checkpoint) WizardOption {
+	return func(w *Wizard) {
+		w.resume = checkpoint
+	}
+}
+
+// LoadCheckpoint reads a checkpoint saved by Run.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read checkpoint file %s", path)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, errors.Wrapf(err, "could not parse checkpoint file %s", path)
+	}
+	return &checkpoint, nil
+}
+
+// Save writes the checkpoint to path, replacing the file atomically so a
+// crash never leaves a truncated checkpoint behind.
+func (c *Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not encode checkpoint")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "could not create checkpoint file")
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "could not write checkpoint file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "could not write checkpoint file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "could not write checkpoint file")
+}
+
+// hashWizardSource returns the hash stored in checkpoints for a wizard file.
+func hashWizardSource(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newCheckpoint captures the progress of a run.
+func (w *Wizard) newCheckpoint(history []historyEntry, nextStepIndex int, state map[string]interface{}) *Checkpoint {
+	checkpoint := &Checkpoint{
+		WizardName:    w.Name,
+		WizardHash:    w.sourceHash,
+		Steps:         make([]CheckpointStep, 0, len(w.Steps)),
+		CurrentStepID: steps.EndStepID,
+		History:       make([]CheckpointEntry, 0, len(history)),
+		State:         state,
+		SavedAt:       time.Now(),
+	}
+	for _, step := range w.Steps {
+		checkpoint.Steps = append(checkpoint.Steps, CheckpointStep{ID: step.ID(), Type: step.Type()})
+	}
+	if nextStepIndex < len(w.Steps) {
+		checkpoint.CurrentStepID = w.Steps[nextStepIndex].ID()
+	}
+	for _, entry := range history {
+		checkpoint.History = append(checkpoint.History, CheckpointEntry{
+			StepID:      w.Steps[entry.index].ID(),
+			StateBefore: entry.stateBefore,
+			Result:      entry.result,
+		})
+	}
+	return checkpoint
+}
+
+// saveCheckpoint saves the progress of the run if a checkpoint file is
+// configured. Failing to save is logged but does not stop the wizard.
+func (w *Wizard) saveCheckpoint(history []historyEntry, nextStepIndex int, state map[string]interface{}) {
+	if w.checkpointFile == "" {
+		return
+	}
+	if err := w.newCheckpoint(history, nextStepIndex, state).Save(w.checkpointFile); err != nil {
+		log.Warn().Err(err).Str("checkpointFile", w.checkpointFile).Msg("Could not save checkpoint")
+	}
+}
+
+// restoreCheckpoint rebuilds the runner's position from a checkpoint. If the
+// wizard file changed since the checkpoint was saved, the run can still be
+// resumed as long as every step it went through, and the step to run next,
+// still exist with the same type; otherwise an error is returned.
+func (w *Wizard) restoreCheckpoint(checkpoint *Checkpoint) ([]historyEntry, int, map[string]interface{}, error) {
+	if checkpoint.WizardHash != w.sourceHash {
+		var incompatible []string
+		check := func(id string) {
+			if id == steps.EndStepID {
+				return
+			}
+			savedType := ""
+			for _, s := range checkpoint.Steps {
+				if s.ID == id {
+					savedType = s.Type
+				}
+			}
+			index := w.stepIndex(id)
+			switch {
+			case index == -1:
+				incompatible = append(incompatible, "step '"+id+"' was removed")
+			case savedType != "" && w.Steps[index].Type() != savedType:
+				incompatible = append(incompatible, "step '"+id+"' changed from "+savedType+" to "+w.Steps[index].Type())
+			}
+		}
+		for _, entry := range checkpoint.History {
+			check(entry.StepID)
+		}
+		check(checkpoint.CurrentStepID)
+		if len(incompatible) > 0 {
+			return nil, 0, nil, errors.Errorf("cannot resume: the wizard changed since the checkpoint was saved (%s)", strings.Join(incompatible, "; "))
+		}
+		log.Warn().Str("wizardName", w.Name).Msg("Wizard file changed since the checkpoint was saved, resuming with the steps that still match")
+	}
+
+	history := make([]historyEntry, 0, len(checkpoint.History))
+	for _, entry := range checkpoint.History {
+		history = append(history, historyEntry{
+			index:       w.stepIndex(entry.StepID),
+			stateBefore: entry.StateBefore,
+			result:      entry.Result,
+		})
+	}
+	state := checkpoint.State
+	if state == nil {
+		state = map[string]interface{}{}
+	}
+	return history, w.stepIndex(checkpoint.CurrentStepID), state, nil
+}
@@ -0,0 +1,232 @@
+package wizard
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CheckpointState is a wizard state saved in a checkpoint. JSON has no
+// integers, dates or typed lists, so the state is saved together with the Go
+// type of every value that would not come back as it was (an int64 from an
+// integer field, a time.Time from a date field, a []string from a callback),
+// and the values are converted back when the checkpoint is loaded:
+//
+//	{"values": {"count": 3, "tags": ["a"]}, "types": {"/count": "int64", "/tags": "[]string"}}
+//
+// Types are keyed by JSON pointer, so values nested in maps and lists keep
+// their types too. Values of other types (structs, for example) come back as
+// JSON decodes them.
+type CheckpointState map[string]interface{}
+
+type checkpointStateJSON struct {
+	Values map[string]interface{} `json:"values"`
+	Types  map[string]string      `json:"types,omitempty"`
+}
+
+// MarshalJSON writes the values with their types.
+func (s CheckpointState) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	types := map[string]string{}
+	recordTypes(map[string]interface{}(s), "", types)
+	if len(types) == 0 {
+		types = nil
+	}
+	return json.Marshal(checkpointStateJSON{Values: s, Types: types})
+}
+
+// UnmarshalJSON reads the values and converts them back to their types.
+func (s *CheckpointState) UnmarshalJSON(data []byte) error {
+	var raw *checkpointStateJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*s = nil
+		return nil
+	}
+	values := raw.Values
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	for pointer, name := range raw.Types {
+		if err := restoreType(values, pointer, name); err != nil {
+			return errors.Wrapf(err, "could not restore %s", pointer)
+		}
+	}
+	*s = values
+	return nil
+}
+
+// scalarTypes are the types that are saved with their values, by name.
+// Slices and string-keyed maps of them are saved too.
+var scalarTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), "", false,
+		time.Time{}, time.Duration(0),
+	} {
+		t := reflect.TypeOf(v)
+		scalarTypes[t.String()] = t
+	}
+}
+
+// typeByName returns the type a type name from a checkpoint stands for.
+func typeByName(name string) (reflect.Type, bool) {
+	switch {
+	case strings.HasPrefix(name, "[]"):
+		elem, ok := typeByName(strings.TrimPrefix(name, "[]"))
+		if !ok {
+			return nil, false
+		}
+		return reflect.SliceOf(elem), true
+	case strings.HasPrefix(name, "map[string]"):
+		elem, ok := typeByName(strings.TrimPrefix(name, "map[string]"))
+		if !ok {
+			return nil, false
+		}
+		return reflect.MapOf(reflect.TypeOf(""), elem), true
+	default:
+		t, ok := scalarTypes[name]
+		return t, ok
+	}
+}
+
+// recordTypes adds the type of every value inside v that JSON would not
+// bring back, keyed by JSON pointer.
+func recordTypes(v interface{}, pointer string, types map[string]string) {
+	switch t := v.(type) {
+	case nil, string, bool, float64, []byte:
+	case map[string]interface{}:
+		for key, item := range t {
+			recordTypes(item, pointer+"/"+escapePointer(key), types)
+		}
+	case []interface{}:
+		for i, item := range t {
+			recordTypes(item, pointer+"/"+strconv.Itoa(i), types)
+		}
+	default:
+		name := reflect.TypeOf(v).String()
+		if _, ok := typeByName(name); ok {
+			types[pointer] = name
+		}
+	}
+}
+
+// restoreType converts the value at pointer in values to the named type.
+func restoreType(values map[string]interface{}, pointer string, name string) error {
+	t, ok := typeByName(name)
+	if !ok {
+		return errors.Errorf("unsupported type %s", name)
+	}
+	parts := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	var parent interface{} = values
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			key := unescapePointer(part)
+			item, ok := p[key]
+			if !ok {
+				return errors.New("no value")
+			}
+			if last {
+				converted, err := convertTo(item, t)
+				if err != nil {
+					return err
+				}
+				p[key] = converted
+				return nil
+			}
+			parent = item
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(p) {
+				return errors.New("no value")
+			}
+			if last {
+				converted, err := convertTo(p[index], t)
+				if err != nil {
+					return err
+				}
+				p[index] = converted
+				return nil
+			}
+			parent = p[index]
+		default:
+			return errors.New("no value")
+		}
+	}
+	return nil
+}
+
+// convertTo converts a value decoded from JSON to t.
+func convertTo(v interface{}, t reflect.Type) (interface{}, error) {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("expected a time, got %T", v)
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected a list, got %T", v)
+		}
+		ret := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			converted, err := convertTo(item, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			ret.Index(i).Set(reflect.ValueOf(converted))
+		}
+		return ret.Interface(), nil
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected a map, got %T", v)
+		}
+		ret := reflect.MakeMapWithSize(t, len(m))
+		for key, item := range m {
+			converted, err := convertTo(item, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			ret.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(converted))
+		}
+		return ret.Interface(), nil
+	default:
+		value := reflect.ValueOf(v)
+		if !value.IsValid() || !value.Type().ConvertibleTo(t) {
+			return nil, errors.Errorf("cannot convert %T to %s", v, t)
+		}
+		return value.Convert(t).Interface(), nil
+	}
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(part string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+}
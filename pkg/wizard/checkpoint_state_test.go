@@ -0,0 +1,85 @@
+package wizard
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckpointStateRoundTrip(t *testing.T) {
+	due := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"string", "hello"},
+		{"bool", true},
+		{"float64", 2.5},
+		{"nil", nil},
+		{"int", 3},
+		{"int64", int64(42)},
+		{"uint8", uint8(7)},
+		{"float32", float32(1.5)},
+		{"time", due},
+		{"duration", 90 * time.Second},
+		{"string list", []string{"a", "b"}},
+		{"empty string list", []string{}},
+		{"int64 list", []int64{1, 2}},
+		{"string map", map[string]string{"a": "b"}},
+		{"list of string lists", [][]string{{"a"}, {"b", "c"}}},
+		{"mixed list", []interface{}{"a", int64(1), due}},
+		{"nested map", map[string]interface{}{"owner": map[string]interface{}{"id": 7, "since": due}}},
+		{"key with a slash", map[string]interface{}{"a/b~c": int64(1)}},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(CheckpointState{"v": tt.value})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		var restored CheckpointState
+		if err := json.Unmarshal(data, &restored); err != nil {
+			t.Errorf("%s: unexpected error: %v (%s)", tt.name, err, data)
+			continue
+		}
+		if got := restored["v"]; !reflect.DeepEqual(got, tt.value) {
+			t.Errorf("%s: expected %#v, got %#v (%s)", tt.name, tt.value, got, data)
+		}
+	}
+}
+
+func TestCheckpointStateUntyped(t *testing.T) {
+	data, err := json.Marshal(CheckpointState{"name": "x", "list": []interface{}{"a", true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"types"`) {
+		t.Errorf("expected no types for values JSON brings back, got %s", data)
+	}
+
+	var null CheckpointState
+	if err := json.Unmarshal([]byte("null"), &null); err != nil || null != nil {
+		t.Errorf("expected a nil state, got %v (%v)", null, err)
+	}
+}
+
+func TestCheckpointStateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown type", `{"values": {"a": 1}, "types": {"/a": "complex128"}}`},
+		{"missing value", `{"values": {}, "types": {"/a": "int"}}`},
+		{"bad time", `{"values": {"a": "yesterday"}, "types": {"/a": "time.Time"}}`},
+		{"not a list", `{"values": {"a": "x"}, "types": {"/a": "[]string"}}`},
+		{"index out of range", `{"values": {"a": []}, "types": {"/a/0": "int"}}`},
+	}
+	for _, tt := range tests {
+		var state CheckpointState
+		if err := json.Unmarshal([]byte(tt.data), &state); err == nil {
+			t.Errorf("%s: expected an error, got %v", tt.name, state)
+		}
+	}
+}
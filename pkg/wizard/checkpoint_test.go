@@ -0,0 +1,102 @@
+package wizard
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+)
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	wizard := `
+name: Resume
+steps:
+  - {id: a, type: action, action_type: function, function_name: visit, arguments: {id: a}, show_progress: false, show_completion: false, output_key: a_done}
+  - {id: b, type: action, action_type: function, function_name: fail_once, show_progress: false, show_completion: false}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false}
+`
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	failed := false
+	failOnce := WithActionCallback("fail_once", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+		if !failed {
+			failed = true
+			return nil, errors.New("interrupted")
+		}
+		return nil, nil
+	})
+
+	visited, _, err := visitWizard(t, wizard, failOnce, WithCheckpointFile(path))
+	if err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	if !reflect.DeepEqual(visited, []string{"a"}) {
+		t.Errorf("expected only a to run, got %v", visited)
+	}
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.CurrentStepID != "b" || len(checkpoint.History) != 1 || checkpoint.State["a_done"] != true {
+		t.Errorf("expected to resume at b after a, got %+v", checkpoint)
+	}
+
+	visited, state, err := visitWizard(t, wizard, failOnce, WithCheckpointFile(path), WithResume(checkpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(visited, []string{"c"}) {
+		t.Errorf("expected only c to run after resuming, got %v", visited)
+	}
+	if state["a_done"] != true {
+		t.Errorf("expected the state of the first run, got %v", state)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint to be removed when the wizard finishes, got %v", err)
+	}
+}
+
+func TestRestoreChangedWizard(t *testing.T) {
+	w := &Wizard{sourceHash: "new", Steps: steps.WizardSteps{
+		&steps.InfoStep{BaseStep: steps.BaseStep{StepID: "a", StepType: "info"}},
+		&steps.InfoStep{BaseStep: steps.BaseStep{StepID: "b", StepType: "info"}},
+		&steps.InfoStep{BaseStep: steps.BaseStep{StepID: "new", StepType: "info"}},
+	}}
+	saved := []CheckpointStep{{ID: "a", Type: "info"}, {ID: "b", Type: "form"}, {ID: "c", Type: "info"}}
+
+	tests := []struct {
+		name    string
+		history []string
+		current string
+		problem string
+	}{
+		{"same steps", []string{"a"}, "end", ""},
+		{"removed step", []string{"a"}, "c", "step 'c' was removed"},
+		{"changed type", []string{"a", "b"}, "end", "step 'b' changed from form to info"},
+	}
+	for _, tt := range tests {
+		checkpoint := &Checkpoint{WizardHash: "old", Steps: saved, CurrentStepID: tt.current}
+		for _, id := range tt.history {
+			checkpoint.History = append(checkpoint.History, CheckpointEntry{StepID: id})
+		}
+		history, index, _, err := w.restoreCheckpoint(checkpoint)
+		if tt.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if len(history) != len(tt.history) || index != len(w.Steps) {
+			t.Errorf("%s: expected to resume at the end after %d steps, got %d after %d", tt.name, len(tt.history), index, len(history))
+		}
+	}
+}
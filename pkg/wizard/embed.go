@@ -0,0 +1,46 @@
+package wizard
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CompletedMsg is emitted by the model returned by BuildBubbleTeaModel when
+// the wizard finished, with its final state.
+type CompletedMsg struct {
+	Wizard *Wizard
+	State  map[string]interface{}
+}
+
+// AbortedMsg is emitted by the model returned by BuildBubbleTeaModel when the
+// wizard stopped early: Err is steps.ErrUserAborted if the user aborted it,
+// or the error of the failing step. State is the state at that point.
+type AbortedMsg struct {
+	Wizard *Wizard
+	State  map[string]interface{}
+	Err    error
+}
+
+// BuildBubbleTeaModel returns the wizard as a Bubble Tea model that a parent
+// program can embed, like pkg.Form.BuildBubbleTeaModel does for a single form.
+// The model shows the same header, sidebar and step views as Run, sized to the
+// tea.WindowSizeMsg the parent forwards to it, and never quits the program
+// (nor touches the alt-screen): when the wizard ends it emits CompletedMsg or
+// AbortedMsg.
+//
+// The parent must pass every message to the model's Update while it is
+// active, including messages it does not know: the steps are run in the
+// background and talk to the model through them. Use
+// BuildBubbleTeaModelWithContext to be able to cancel the run.
+func (w *Wizard) BuildBubbleTeaModel(initialState map[string]interface{}) tea.Model {
+	return w.BuildBubbleTeaModelWithContext(context.Background(), initialState)
+}
+
+// BuildBubbleTeaModelWithContext is BuildBubbleTeaModel with a context that
+// cancels the run when it ends; the model then emits AbortedMsg.
+func (w *Wizard) BuildBubbleTeaModelWithContext(ctx context.Context, initialState map[string]interface{}) tea.Model {
+	m := w.newModel(ctx, initialState)
+	m.embedded = true
+	return m
+}
@@ -0,0 +1,146 @@
+package wizard
+
+import (
+	"sort"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+// EdgeKind tells why the runner can go from one step to another.
+type EdgeKind string
+
+const (
+	// EdgeLinear leads to the following step in the file.
+	EdgeLinear EdgeKind = "linear"
+	// EdgeNextStep follows the step's next_step.
+	EdgeNextStep EdgeKind = "next_step"
+	// EdgeChoice follows a choice of a decision step; its label is the choice.
+	EdgeChoice EdgeKind = "choice"
+	// EdgeSkip bypasses the step when its skip_condition holds; its label is
+	// the condition.
+	EdgeSkip EdgeKind = "skip"
+	// EdgeOnError follows the on_error goto of an action step.
+	EdgeOnError EdgeKind = "on_error"
+	// EdgeDynamic is a navigation callback, which may jump to any step; its
+	// label is the callback name and its target AnyStep.
+	EdgeDynamic EdgeKind = "dynamic"
+)
+
+// AnyStep is the target of dynamic edges.
+const AnyStep = "*"
+
+// GraphNode is a step of the wizard graph. The graph ends with a node for
+// steps.EndStepID.
+type GraphNode struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+}
+
+// GraphEdge is a possible transition between two steps.
+type GraphEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  EdgeKind `json:"kind"`
+	Label string   `json:"label,omitempty"`
+}
+
+// Graph is the flow of a wizard as the runner can walk it forward (going back
+// and editing from a summary are not edges).
+type Graph struct {
+	Name  string      `json:"name,omitempty"`
+	Start string      `json:"start"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph builds the step graph of the wizard from its definition.
+func (w *Wizard) Graph() *Graph {
+	g := &Graph{Name: w.Name, Start: steps.EndStepID}
+	if len(w.Steps) > 0 {
+		g.Start = w.Steps[0].ID()
+	}
+
+	// following returns the step after index i, or the end.
+	following := func(i int) string {
+		if i+1 < len(w.Steps) {
+			return w.Steps[i+1].ID()
+		}
+		return steps.EndStepID
+	}
+
+	for i, step := range w.Steps {
+		id := step.ID()
+		bs := step.GetBaseStep()
+		g.Nodes = append(g.Nodes, GraphNode{ID: id, Type: step.Type(), Title: step.Title()})
+
+		if cond := step.SkipCondition(); cond != "" {
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: following(i), Kind: EdgeSkip, Label: cond})
+		}
+
+		ds, isDecision := step.(*steps.DecisionStep)
+		switch {
+		case isDecision && len(ds.NextStepMap) > 0:
+			choices := ds.Choices
+			if len(choices) == 0 {
+				for choice := range ds.NextStepMap {
+					if choice != steps.NextStepMapDefault {
+						choices = append(choices, choice)
+					}
+				}
+				sort.Strings(choices)
+			}
+			for _, choice := range choices {
+				target := ds.NextStepFor(choice)
+				if target == "" {
+					target = following(i)
+				}
+				g.Edges = append(g.Edges, GraphEdge{From: id, To: target, Kind: EdgeChoice, Label: choice})
+			}
+		case bs.NextStep != "":
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: bs.NextStep, Kind: EdgeNextStep})
+		default:
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: following(i), Kind: EdgeLinear})
+		}
+
+		if as, ok := step.(*steps.ActionStep); ok && as.OnError != nil && as.OnError.Goto != "" {
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: as.OnError.Goto, Kind: EdgeOnError})
+		}
+		if name := step.NavigationCallback(); name != "" {
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: AnyStep, Kind: EdgeDynamic, Label: name})
+		}
+	}
+
+	g.Nodes = append(g.Nodes, GraphNode{ID: steps.EndStepID, Type: steps.EndStepID})
+	return g
+}
+
+// Reachable returns the IDs of the nodes that can be reached from the node
+// from, including from itself. Dynamic edges reach every node.
+func (g *Graph) Reachable(from string) map[string]bool {
+	reached := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.Edges {
+			if edge.From != current {
+				continue
+			}
+			targets := []string{edge.To}
+			if edge.To == AnyStep {
+				targets = targets[:0]
+				for _, node := range g.Nodes {
+					targets = append(targets, node.ID)
+				}
+			}
+			for _, target := range targets {
+				if !reached[target] {
+					reached[target] = true
+					queue = append(queue, target)
+				}
+			}
+		}
+	}
+	return reached
+}
@@ -0,0 +1,156 @@
+package wizard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+// Mermaid renders the graph as a Mermaid flowchart. Skip, on_error and
+// dynamic edges are dotted; dynamic edges lead to a single "any step" node.
+func (g *Graph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+
+	// Step IDs may contain characters Mermaid does not accept in node IDs,
+	// and "end" is a keyword, so nodes are numbered.
+	ids := map[string]string{}
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+	ids[AnyStep] = "any"
+
+	sb.WriteString("    start((start)) --> " + ids[g.Start] + "\n")
+	for _, node := range g.Nodes {
+		left, right := mermaidShape(node.Type)
+		fmt.Fprintf(&sb, "    %s%s\"%s\"%s\n", ids[node.ID], left, mermaidEscape(nodeLabel(node, "<br/>")), right)
+	}
+	if g.hasDynamicEdges() {
+		sb.WriteString("    any{{\"any step\"}}\n")
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Kind == EdgeSkip || edge.Kind == EdgeOnError || edge.Kind == EdgeDynamic {
+			arrow = "-.->"
+		}
+		if label := edgeLabel(edge); label != "" {
+			arrow += "|\"" + mermaidEscape(label) + "\"|"
+		}
+		fmt.Fprintf(&sb, "    %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+	return sb.String()
+}
+
+// DOT renders the graph in the Graphviz DOT language. Skip, on_error and
+// dynamic edges are dashed; dynamic edges lead to a single "any step" node.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(g.Name))
+	sb.WriteString("    rankdir=TB;\n")
+	sb.WriteString("    node [fontname=\"Helvetica\"];\n")
+	sb.WriteString("    edge [fontname=\"Helvetica\", fontsize=10];\n")
+	sb.WriteString("    \"\" [shape=point];\n")
+	fmt.Fprintf(&sb, "    \"\" -> %s;\n", dotQuote(g.Start))
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&sb, "    %s [label=%s, shape=%s];\n",
+			dotQuote(node.ID), dotQuote(nodeLabel(node, "\n")), dotShape(node.Type))
+	}
+	if g.hasDynamicEdges() {
+		fmt.Fprintf(&sb, "    %s [label=\"any step\", shape=hexagon];\n", dotQuote(AnyStep))
+	}
+
+	for _, edge := range g.Edges {
+		var attrs []string
+		if label := edgeLabel(edge); label != "" {
+			attrs = append(attrs, "label="+dotQuote(label))
+		}
+		if edge.Kind == EdgeSkip || edge.Kind == EdgeOnError || edge.Kind == EdgeDynamic {
+			attrs = append(attrs, "style=dashed")
+		}
+		line := fmt.Sprintf("    %s -> %s", dotQuote(edge.From), dotQuote(edge.To))
+		if len(attrs) > 0 {
+			line += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		sb.WriteString(line + ";\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (g *Graph) hasDynamicEdges() bool {
+	for _, edge := range g.Edges {
+		if edge.To == AnyStep {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeLabel is the step ID, followed by the title on a second line.
+func nodeLabel(node GraphNode, newline string) string {
+	if node.Title == "" || node.Title == node.ID {
+		return node.ID
+	}
+	return node.ID + newline + node.Title
+}
+
+func edgeLabel(edge GraphEdge) string {
+	switch edge.Kind {
+	case EdgeChoice:
+		return edge.Label
+	case EdgeSkip:
+		return "skip if " + edge.Label
+	case EdgeOnError:
+		return "on error"
+	case EdgeDynamic:
+		return "navigation: " + edge.Label
+	default:
+		return ""
+	}
+}
+
+func mermaidShape(stepType string) (string, string) {
+	switch stepType {
+	case "decision":
+		return "{", "}"
+	case "action":
+		return "[[", "]]"
+	case "info":
+		return "(", ")"
+	case "summary":
+		return "([", "])"
+	case steps.EndStepID:
+		return "((", "))"
+	default:
+		return "[", "]"
+	}
+}
+
+// mermaidEscape replaces the characters that end a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
+
+func dotShape(stepType string) string {
+	switch stepType {
+	case "decision":
+		return "diamond"
+	case "action":
+		return "box3d"
+	case "info":
+		return "note"
+	case "summary":
+		return "folder"
+	case steps.EndStepID:
+		return "doublecircle"
+	default:
+		return "box"
+	}
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
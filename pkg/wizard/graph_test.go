@@ -0,0 +1,139 @@
+package wizard
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const graphWizard = `
+name: Graph
+steps:
+  - {id: intro, type: info, content: Hi, title: Welcome}
+  - id: pick
+    type: decision
+    target_key: plan
+    choices: [free, pro, trial]
+    next_step_map:
+      free: done
+      pro: billing
+  - {id: billing, type: form, skip_condition: "paid", form: {groups: [{fields: [{type: input, key: card}]}]}}
+  - {id: charge, type: action, action_type: function, function_name: charge, next_step: done, on_error: {then: goto, goto: failed}}
+  - {id: failed, type: info, content: Sorry, next_step: end}
+  - {id: orphan, type: info, content: Never shown}
+  - {id: done, type: summary, navigation: choose}
+`
+
+func TestGraph(t *testing.T) {
+	w, err := LoadWizard(writeWizard(t, graphWizard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := w.Graph()
+
+	if g.Start != "intro" || g.Name != "Graph" {
+		t.Errorf("expected the graph Graph to start at intro, got %s starting at %s", g.Name, g.Start)
+	}
+	if last := g.Nodes[len(g.Nodes)-1]; last.ID != "end" || last.Type != "end" {
+		t.Errorf("expected the graph to end with the end node, got %+v", last)
+	}
+
+	expected := []GraphEdge{
+		{From: "intro", To: "pick", Kind: EdgeLinear},
+		{From: "pick", To: "done", Kind: EdgeChoice, Label: "free"},
+		{From: "pick", To: "billing", Kind: EdgeChoice, Label: "pro"},
+		{From: "pick", To: "billing", Kind: EdgeChoice, Label: "trial"},
+		{From: "billing", To: "charge", Kind: EdgeSkip, Label: "paid"},
+		{From: "billing", To: "charge", Kind: EdgeLinear},
+		{From: "charge", To: "done", Kind: EdgeNextStep},
+		{From: "charge", To: "failed", Kind: EdgeOnError},
+		{From: "failed", To: "end", Kind: EdgeNextStep},
+		{From: "orphan", To: "done", Kind: EdgeLinear},
+		{From: "done", To: "end", Kind: EdgeLinear},
+		{From: "done", To: AnyStep, Kind: EdgeDynamic, Label: "choose"},
+	}
+	if !reflect.DeepEqual(g.Edges, expected) {
+		t.Errorf("expected the edges\n%+v\ngot\n%+v", expected, g.Edges)
+	}
+}
+
+func TestGraphReachable(t *testing.T) {
+	g := &Graph{
+		Start: "a",
+		Nodes: []GraphNode{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "end"}},
+		Edges: []GraphEdge{
+			{From: "a", To: "b", Kind: EdgeLinear},
+			{From: "b", To: "end", Kind: EdgeNextStep},
+			{From: "c", To: "d", Kind: EdgeLinear},
+			{From: "d", To: AnyStep, Kind: EdgeDynamic},
+		},
+	}
+
+	tests := []struct {
+		from     string
+		expected []string
+	}{
+		{"a", []string{"a", "b", "end"}},
+		{"b", []string{"b", "end"}},
+		{"c", []string{"a", "b", "c", "d", "end"}},
+		{"end", []string{"end"}},
+	}
+	for _, tt := range tests {
+		reached := g.Reachable(tt.from)
+		got := []string{}
+		for _, node := range g.Nodes {
+			if reached[node.ID] {
+				got = append(got, node.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("from %s: expected to reach %v, got %v", tt.from, tt.expected, got)
+		}
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	w, err := LoadWizard(writeWizard(t, graphWizard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := w.Graph()
+
+	tests := []struct {
+		format   string
+		output   string
+		expected []string
+	}{
+		{"mermaid", g.Mermaid(), []string{
+			"flowchart TD\n",
+			"start((start)) --> n0\n",
+			`n0("intro<br/>Welcome")`,
+			`n1{"pick"}`,
+			`n3[["charge"]]`,
+			`n7(("end"))`,
+			`any{{"any step"}}`,
+			`n1 -->|"free"| n6`,
+			`n2 -.->|"skip if paid"| n3`,
+			`n3 -.->|"on error"| n4`,
+			`n6 -.->|"navigation: choose"| any`,
+		}},
+		{"dot", g.DOT(), []string{
+			`digraph "Graph" {`,
+			`"" -> "intro";`,
+			`"intro" [label="intro\nWelcome", shape=note];`,
+			`"pick" [label="pick", shape=diamond];`,
+			`"end" [label="end", shape=doublecircle];`,
+			`"*" [label="any step", shape=hexagon];`,
+			`"pick" -> "done" [label="free"];`,
+			`"billing" -> "charge" [label="skip if paid", style=dashed];`,
+			`"intro" -> "pick";`,
+		}},
+	}
+	for _, tt := range tests {
+		for _, line := range tt.expected {
+			if !strings.Contains(tt.output, line) {
+				t.Errorf("%s: expected the output to contain %q, got\n%s", tt.format, line, tt.output)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package wizard
+
+import (
+	"maps"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+// historyEntry records a step that was executed, so the runner can return to
+// it when the user goes back.
+type historyEntry struct {
+	// index is the position of the step in Wizard.Steps.
+	index int
+	// stateBefore is the wizard state right before the step ran (before its
+	// 'before' callback). Going back restores it, which drops every key set by
+	// the step and the steps after it.
+	stateBefore map[string]interface{}
+	// result is what the step contributed to the state. It is shown again as
+	// the step's answers when the user comes back to it.
+	result map[string]interface{}
+}
+
+// popHistory removes steps from the end of the history up to and including
+// the most recent interactive step, and returns that step's entry. Action
+// steps on the way are unwound as well: re-running them would immediately
+// move forward again. It returns false if the history is empty.
+func (w *Wizard) popHistory(history []historyEntry) (historyEntry, []historyEntry, bool) {
+	for len(history) > 0 {
+		entry := history[len(history)-1]
+		history = history[:len(history)-1]
+		if _, isAction := w.Steps[entry.index].(*steps.ActionStep); !isAction || len(history) == 0 {
+			return entry, history, true
+		}
+	}
+	return historyEntry{}, history, false
+}
+
+// withAnswers returns the state a revisited step is executed with: the
+// restored state plus the answers the step gave last time, so forms and
+// decisions start from them.
+func withAnswers(state map[string]interface{}, answers map[string]interface{}) map[string]interface{} {
+	if len(answers) == 0 {
+		return state
+	}
+	ret := maps.Clone(state)
+	for k, v := range answers {
+		ret[k] = v
+	}
+	return ret
+}
+
+// findSource returns the position in history of the most recent step whose
+// result contains key, or -1.
+func findSource(history []historyEntry, key string) int {
+	for i := len(history) - 1; i >= 0; i-- {
+		if _, ok := history[i].result[key]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// fieldSources maps every state key set by a step in history to that step.
+func (w *Wizard) fieldSources(history []historyEntry) map[string]steps.FieldSource {
+	sources := map[string]steps.FieldSource{}
+	for _, entry := range history {
+		step := w.Steps[entry.index]
+		for k := range entry.result {
+			sources[k] = steps.FieldSource{StepID: step.ID(), StepTitle: step.Title()}
+		}
+	}
+	return sources
+}
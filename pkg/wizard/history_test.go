@@ -0,0 +1,153 @@
+package wizard
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+func TestPopHistory(t *testing.T) {
+	w := &Wizard{Steps: steps.WizardSteps{
+		&steps.InfoStep{BaseStep: steps.BaseStep{StepID: "intro", StepType: "info"}},
+		&steps.ActionStep{BaseStep: steps.BaseStep{StepID: "fetch", StepType: "action"}},
+		&steps.ActionStep{BaseStep: steps.BaseStep{StepID: "save", StepType: "action"}},
+		&steps.InfoStep{BaseStep: steps.BaseStep{StepID: "outro", StepType: "info"}},
+	}}
+
+	tests := []struct {
+		name      string
+		history   []int
+		expected  int
+		remaining []int
+		ok        bool
+	}{
+		{"empty", nil, 0, nil, false},
+		{"interactive step", []int{0}, 0, []int{}, true},
+		{"unwinds action steps", []int{0, 1, 2}, 0, []int{}, true},
+		{"stops at the latest interactive step", []int{0, 1, 3}, 3, []int{0, 1}, true},
+		{"first step is an action", []int{1, 2}, 1, []int{}, true},
+	}
+	for _, tt := range tests {
+		history := []historyEntry{}
+		for _, index := range tt.history {
+			history = append(history, historyEntry{index: index})
+		}
+		entry, rest, ok := w.popHistory(history)
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok to be %v, got %v", tt.name, tt.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		remaining := []int{}
+		for _, e := range rest {
+			remaining = append(remaining, e.index)
+		}
+		if entry.index != tt.expected || !reflect.DeepEqual(remaining, tt.remaining) {
+			t.Errorf("%s: expected to go back to %d leaving %v, got %d leaving %v", tt.name, tt.expected, tt.remaining, entry.index, remaining)
+		}
+	}
+}
+
+func TestWithAnswers(t *testing.T) {
+	state := map[string]interface{}{"name": "old", "other": 1}
+	got := withAnswers(state, map[string]interface{}{"name": "new"})
+	expected := map[string]interface{}{"name": "new", "other": 1}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if state["name"] != "old" {
+		t.Errorf("expected the restored state to be left alone, got %v", state)
+	}
+}
+
+func TestRunGoBack(t *testing.T) {
+	wentBack := false
+	visited, state, err := visitWizard(t, `
+steps:
+  - {id: a, type: action, action_type: function, function_name: visit, arguments: {id: a}, show_progress: false, show_completion: false, output_key: a_done}
+  - {id: b, type: action, action_type: function, function_name: back_once, show_progress: false, show_completion: false}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false}
+`, WithActionCallback("back_once", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+		if !wentBack {
+			wentBack = true
+			if state["a_done"] != true {
+				t.Errorf("expected a to have run before b, got %v", state)
+			}
+			return nil, steps.ErrGoBack
+		}
+		return nil, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a", "a", "c"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected the steps %v to run, got %v", expected, visited)
+	}
+	if state["a_done"] != true {
+		t.Errorf("expected a to have run again, got %v", state)
+	}
+}
+
+func TestRunEditReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		wizard   string
+		expected []string
+	}{
+		{
+			name: "later steps keep their answers",
+			wizard: `
+steps:
+  - {id: a, type: action, action_type: function, function_name: count, show_progress: false, show_completion: false, output_key: name}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false, output_key: color}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false}
+  - {id: d, type: action, action_type: function, function_name: edit_once, show_progress: false, show_completion: false}
+`,
+			expected: []string{"b", "c"},
+		},
+		{
+			name: "later steps are asked again when the path changes",
+			wizard: `
+steps:
+  - {id: a, type: action, action_type: function, function_name: count, show_progress: false, show_completion: false, output_key: name}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false, output_key: color, skip_condition: "name == 2"}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false}
+  - {id: d, type: action, action_type: function, function_name: edit_once, show_progress: false, show_completion: false}
+`,
+			expected: []string{"b", "c", "c"},
+		},
+	}
+	for _, tt := range tests {
+		count, edits := 0, 0
+		visited, state, err := visitWizard(t, tt.wizard,
+			WithActionCallback("count", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+				count++
+				return count, nil
+			}),
+			WithActionCallback("edit_once", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+				edits++
+				if edits == 1 {
+					return nil, &steps.EditRequest{Key: "name"}
+				}
+				return nil, nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if count != 2 || edits != 2 {
+			t.Errorf("%s: expected the edited step and the summary to run twice, got %d and %d", tt.name, count, edits)
+		}
+		if !reflect.DeepEqual(visited, tt.expected) {
+			t.Errorf("%s: expected the steps %v to run, got %v", tt.name, tt.expected, visited)
+		}
+		if state["name"] != 2 {
+			t.Errorf("%s: expected the edited answer 2, got %v", tt.name, state["name"])
+		}
+	}
+}
@@ -16,6 +16,7 @@ func (w *Wizard) UnmarshalYAML(node *yaml.Node) error {
 		Name        string                 `yaml:"name"`
 		Description string                 `yaml:"description,omitempty"`
 		Theme       string                 `yaml:"theme,omitempty"`
+		Timeout     string                 `yaml:"timeout,omitempty"`
 		GlobalState map[string]interface{} `yaml:"global_state,omitempty"`
 		StepsNode   yaml.Node              `yaml:"steps"` // Capture steps node separately
 	}
@@ -29,6 +30,7 @@ func (w *Wizard) UnmarshalYAML(node *yaml.Node) error {
 				Name        string                 `yaml:"name"`
 				Description string                 `yaml:"description,omitempty"`
 				Theme       string                 `yaml:"theme,omitempty"`
+				Timeout     string                 `yaml:"timeout,omitempty"`
 				GlobalState map[string]interface{} `yaml:"global_state,omitempty"`
 			}
 			var aliasNoSteps WizardAliasNoSteps
@@ -38,6 +40,7 @@ func (w *Wizard) UnmarshalYAML(node *yaml.Node) error {
 			w.Name = aliasNoSteps.Name
 			w.Description = aliasNoSteps.Description
 			w.Theme = aliasNoSteps.Theme
+			w.Timeout = aliasNoSteps.Timeout
 			w.GlobalState = aliasNoSteps.GlobalState
 			w.Steps = []steps.Step{} // Initialize empty steps slice
 			return nil
@@ -48,6 +51,7 @@ func (w *Wizard) UnmarshalYAML(node *yaml.Node) error {
 	w.Name = alias.Name
 	w.Description = alias.Description
 	w.Theme = alias.Theme
+	w.Timeout = alias.Timeout
 	w.GlobalState = alias.GlobalState
 
 	if alias.StepsNode.Kind != yaml.SequenceNode {
@@ -0,0 +1,49 @@
+package wizard
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+)
+
+// StepMockFunc runs instead of a step: it gets the step and the state the
+// step would start from, and returns the step result merged into the state.
+type StepMockFunc func(ctx context.Context, step steps.Step, state map[string]interface{}) (map[string]interface{}, error)
+
+// WithStepMock replaces the step with the given ID by mock, for example to
+// test a wizard without running its shell or HTTP actions. The before and
+// after callbacks of the step still run.
+func WithStepMock(stepID string, mock StepMockFunc) WizardOption {
+	return func(w *Wizard) {
+		if w.stepMocks == nil {
+			w.stepMocks = make(map[string]StepMockFunc)
+		}
+		w.stepMocks[stepID] = mock
+	}
+}
+
+// validateStepMocks checks that the mocked steps are steps of this wizard.
+func (w *Wizard) validateStepMocks() error {
+	var unknown []string
+	for stepID := range w.stepMocks {
+		if w.stepIndex(stepID) == -1 {
+			unknown = append(unknown, stepID)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.Errorf("mocks given for unknown steps: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// executeStep runs step, or its mock if it has one.
+func (w *Wizard) executeStep(ctx context.Context, step steps.Step, state map[string]interface{}) (map[string]interface{}, error) {
+	if mock, ok := w.stepMocks[step.ID()]; ok {
+		return mock(ctx, step, state)
+	}
+	return step.Execute(ctx, state)
+}
@@ -0,0 +1,471 @@
+package wizard
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+// sidebarWidth is the width of the list of steps next to the current step.
+const sidebarWidth = 28
+
+var (
+	headerStyle        = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	headerCounterStyle = lipgloss.NewStyle().Faint(true).Padding(0, 1)
+	sidebarStyle       = lipgloss.NewStyle().Width(sidebarWidth).Padding(0, 1).
+				Border(lipgloss.NormalBorder(), false, true, false, false)
+	completedStepStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	currentStepStyle   = lipgloss.NewStyle().Bold(true)
+	contentStyle       = lipgloss.NewStyle().Padding(0, 1)
+)
+
+// stepProgress describes the step the runner is about to execute.
+type stepProgress struct {
+	index int
+	title string
+	// completed are the titles of the steps executed so far.
+	completed []string
+}
+
+// stepListener is told by runSteps about every step before it runs.
+type stepListener func(stepProgress)
+
+// stepProgress returns the progress shown while the step at index runs.
+func (w *Wizard) stepProgress(index int, history []historyEntry, state map[string]interface{}) stepProgress {
+	completed := make([]string, 0, len(history))
+	for _, entry := range history {
+		completed = append(completed, stepTitle(w.Steps[entry.index], state))
+	}
+	return stepProgress{
+		index:     index,
+		title:     stepTitle(w.Steps[index], state),
+		completed: completed,
+	}
+}
+
+// stepTitle returns the rendered title of step, or its ID.
+func stepTitle(step steps.Step, state map[string]interface{}) string {
+	title, err := pkg.Interpolate(step.Title(), state)
+	if err != nil || title == "" {
+		return step.ID()
+	}
+	return title
+}
+
+// Messages sent by the runner to the model through its request channel.
+type (
+	stepStartedMsg struct {
+		progress stepProgress
+	}
+	showFormMsg struct {
+		form *huh.Form
+		done chan error
+	}
+	dismissFormMsg struct {
+		form *huh.Form
+	}
+	showModelMsg struct {
+		model tea.Model
+		done  chan tea.Model
+	}
+	dismissModelMsg struct {
+		done chan tea.Model
+	}
+	progressMsg struct {
+		id          int
+		title       string
+		description string
+		show        bool
+	}
+	runnerDoneMsg struct {
+		state map[string]interface{}
+		err   error
+	}
+	// ownedMsg wraps the messages above with the model they are for, so
+	// several embedded wizards can share a parent program.
+	ownedMsg struct {
+		owner *model
+		msg   tea.Msg
+	}
+)
+
+// model is the Bubble Tea model of a wizard run. It runs the step loop in the
+// background and hosts the view of the current step (a form, a model such as
+// the shell viewer, or an action spinner) below a header with the wizard name,
+// the step title and the step number, next to the list of completed steps.
+type model struct {
+	wizard       *Wizard
+	initialState map[string]interface{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	requests     chan tea.Msg
+
+	width  int
+	height int
+
+	progress stepProgress
+	form     *huh.Form
+	formDone chan error
+	sub      tea.Model
+	subDone  chan tea.Model
+
+	spinner             spinner.Model
+	progressID          int
+	progressTitle       string
+	progressDescription string
+	spinning            bool
+
+	// embedded models emit CompletedMsg or AbortedMsg when the wizard ends,
+	// instead of quitting the program.
+	embedded bool
+	aborted  bool
+	finished bool
+	state    map[string]interface{}
+	err      error
+}
+
+var _ tea.Model = &model{}
+
+func (w *Wizard) newModel(ctx context.Context, initialState map[string]interface{}) *model {
+	ctx, cancel := context.WithCancel(ctx)
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	return &model{
+		wizard:       w,
+		initialState: initialState,
+		ctx:          ctx,
+		cancel:       cancel,
+		requests:     make(chan tea.Msg),
+		spinner:      sp,
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(m.runCmd(), m.waitForRequest())
+}
+
+// runCmd runs the step loop, with m as the host of the step views.
+func (m *model) runCmd() tea.Cmd {
+	host := &modelHost{ctx: m.ctx, requests: m.requests}
+	return func() tea.Msg {
+		ctx := pkg.WithHost(m.ctx, host)
+		state, err := m.wizard.runSteps(ctx, m.initialState, func(p stepProgress) {
+			host.send(m.ctx, stepStartedMsg{progress: p})
+		})
+		return ownedMsg{owner: m, msg: runnerDoneMsg{state: state, err: err}}
+	}
+}
+
+// waitForRequest delivers the next message sent by the runner.
+func (m *model) waitForRequest() tea.Cmd {
+	requests := m.requests
+	done := m.ctx.Done()
+	return func() tea.Msg {
+		select {
+		case msg := <-requests:
+			return ownedMsg{owner: m, msg: msg}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	owned, isOwned := msg.(ownedMsg)
+	if isOwned {
+		if owned.owner != m {
+			return m, nil
+		}
+		msg = owned.msg
+	}
+
+	switch msg := msg.(type) {
+	case runnerDoneMsg:
+		m.state, m.err = msg.state, msg.err
+		if m.aborted && m.err != nil {
+			m.err = steps.ErrUserAborted
+		}
+		m.finished = true
+		m.cancel()
+		if !m.embedded {
+			return m, tea.Quit
+		}
+		return m, m.doneCmd()
+
+	case stepStartedMsg:
+		m.progress = msg.progress
+		return m, m.waitForRequest()
+
+	case showFormMsg:
+		m.form, m.formDone = msg.form, msg.done
+		m.form.SubmitCmd = nil
+		m.form.CancelCmd = nil
+		cmds := []tea.Cmd{m.form.Init(), m.waitForRequest()}
+		if m.width > 0 {
+			cmds = append(cmds, m.updateActive(m.contentSize()))
+		}
+		return m, tea.Batch(cmds...)
+
+	case dismissFormMsg:
+		if m.form == msg.form {
+			m.form, m.formDone = nil, nil
+		}
+		return m, m.waitForRequest()
+
+	case showModelMsg:
+		m.sub, m.subDone = msg.model, msg.done
+		cmds := []tea.Cmd{m.ownDone(m.sub.Init()), m.waitForRequest()}
+		if m.width > 0 {
+			cmds = append(cmds, m.updateActive(m.contentSize()))
+		}
+		return m, tea.Batch(cmds...)
+
+	case dismissModelMsg:
+		if m.subDone == msg.done {
+			m.sub, m.subDone = nil, nil
+		}
+		return m, m.waitForRequest()
+
+	case progressMsg:
+		cmds := []tea.Cmd{m.waitForRequest()}
+		if msg.show {
+			m.progressID = msg.id
+			m.progressTitle, m.progressDescription = msg.title, msg.description
+			if !m.spinning {
+				cmds = append(cmds, m.spinner.Tick)
+			}
+			m.spinning = true
+		} else if msg.id == m.progressID {
+			m.spinning = false
+		}
+		return m, tea.Batch(cmds...)
+
+	case pkg.ModelDoneMsg:
+		// Only the hosted model of this wizard ends it (see ownDone)
+		if isOwned && m.sub != nil {
+			m.subDone <- m.sub
+			m.sub, m.subDone = nil, nil
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.spinning {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, m.updateActive(m.contentSize())
+
+	case tea.KeyMsg:
+		switch {
+		case m.form != nil:
+			if key.Matches(msg, pkg.BackKey) {
+				m.finishForm(pkg.ErrGoBack)
+				return m, nil
+			}
+		case m.sub != nil:
+		case msg.String() == "ctrl+c":
+			m.aborted = true
+			m.cancel()
+			return m, nil
+		}
+	}
+
+	return m, m.updateActive(msg)
+}
+
+// doneCmd emits the message that tells the parent of an embedded model how
+// the wizard ended.
+func (m *model) doneCmd() tea.Cmd {
+	w, state, err := m.wizard, m.state, m.err
+	return func() tea.Msg {
+		if err != nil {
+			return AbortedMsg{Wizard: w, State: state, Err: err}
+		}
+		return CompletedMsg{Wizard: w, State: state}
+	}
+}
+
+// updateActive passes msg to the form or model of the current step.
+func (m *model) updateActive(msg tea.Msg) tea.Cmd {
+	switch {
+	case m.form != nil:
+		fm, cmd := m.form.Update(msg)
+		if f, ok := fm.(*huh.Form); ok {
+			m.form = f
+		}
+		switch m.form.State {
+		case huh.StateCompleted:
+			m.finishForm(nil)
+		case huh.StateAborted:
+			m.finishForm(huh.ErrUserAborted)
+		}
+		return cmd
+	case m.sub != nil:
+		sub, cmd := m.sub.Update(msg)
+		m.sub = sub
+		return m.ownDone(cmd)
+	}
+	return nil
+}
+
+// ownDone wraps the ModelDoneMsg that cmd, a command of the hosted model,
+// may emit in an ownedMsg, so it only ends the model of this wizard. Batched
+// and sequenced commands are wrapped too.
+func (m *model) ownDone(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		if _, ok := msg.(pkg.ModelDoneMsg); ok {
+			return ownedMsg{owner: m, msg: msg}
+		}
+		// tea.BatchMsg, and the unexported message of tea.Sequence, are lists
+		// of commands
+		if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == cmdType {
+			for i := 0; i < v.Len(); i++ {
+				if c, ok := v.Index(i).Interface().(tea.Cmd); ok {
+					v.Index(i).Set(reflect.ValueOf(m.ownDone(c)))
+				}
+			}
+		}
+		return msg
+	}
+}
+
+var cmdType = reflect.TypeOf((*tea.Cmd)(nil)).Elem()
+
+// finishForm hands the outcome of the current form to the step waiting for it.
+func (m *model) finishForm(err error) {
+	m.formDone <- err
+	m.form, m.formDone = nil, nil
+}
+
+// contentSize is the size left for the step view by the header and sidebar.
+func (m *model) contentSize() tea.WindowSizeMsg {
+	width := m.width - sidebarWidth - 3
+	if width < 20 {
+		width = m.width
+	}
+	height := m.height - 2
+	if height < 5 {
+		height = m.height
+	}
+	return tea.WindowSizeMsg{Width: width, Height: height}
+}
+
+func (m *model) View() string {
+	if m.finished {
+		return ""
+	}
+
+	header := headerStyle.Render(m.wizard.Name)
+	if m.progress.title != "" {
+		header += headerStyle.Render("· " + m.progress.title)
+	}
+	header += headerCounterStyle.Render(fmt.Sprintf("step %d of %d", m.progress.index+1, len(m.wizard.Steps)))
+
+	var sidebar strings.Builder
+	for _, title := range m.progress.completed {
+		sidebar.WriteString(completedStepStyle.Render("✓ "+title) + "\n")
+	}
+	if m.progress.title != "" {
+		sidebar.WriteString(currentStepStyle.Render("› " + m.progress.title))
+	}
+
+	var content string
+	switch {
+	case m.sub != nil:
+		content = m.sub.View()
+	case m.form != nil:
+		content = m.form.View()
+	case m.spinning:
+		content = fmt.Sprintf("%s %s\n\n%s", m.spinner.View(), m.progressTitle, m.progressDescription)
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		sidebarStyle.Render(sidebar.String()),
+		contentStyle.Width(m.contentSize().Width).Render(content),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", body)
+}
+
+// modelHost implements pkg.Host for the steps run by a model. Its methods are
+// called by the runner and talk to the model through its request channel.
+type modelHost struct {
+	ctx      context.Context
+	requests chan tea.Msg
+
+	mu         sync.Mutex
+	progressID int
+}
+
+var _ pkg.Host = &modelHost{}
+
+// send delivers msg to the model, unless ctx ends first.
+func (h *modelHost) send(ctx context.Context, msg tea.Msg) bool {
+	select {
+	case h.requests <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (h *modelHost) RunForm(ctx context.Context, form *huh.Form) error {
+	done := make(chan error, 1)
+	if !h.send(ctx, showFormMsg{form: form, done: done}) {
+		return ctx.Err()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		h.send(h.ctx, dismissFormMsg{form: form})
+		return ctx.Err()
+	}
+}
+
+func (h *modelHost) RunModel(ctx context.Context, model tea.Model) (tea.Model, error) {
+	done := make(chan tea.Model, 1)
+	if !h.send(ctx, showModelMsg{model: model, done: done}) {
+		return nil, ctx.Err()
+	}
+	select {
+	case final := <-done:
+		return final, nil
+	case <-ctx.Done():
+		h.send(h.ctx, dismissModelMsg{done: done})
+		return nil, ctx.Err()
+	}
+}
+
+func (h *modelHost) StartProgress(title string, description string) func() {
+	h.mu.Lock()
+	h.progressID++
+	id := h.progressID
+	h.mu.Unlock()
+
+	h.send(h.ctx, progressMsg{id: id, title: title, description: description, show: true})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.send(h.ctx, progressMsg{id: id})
+		})
+	}
+}
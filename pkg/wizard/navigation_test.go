@@ -0,0 +1,135 @@
+package wizard
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+)
+
+// visitWizard loads a wizard whose action steps call `visit` with their ID as
+// the `id` argument, runs its steps and returns the IDs in the order they
+// ran. `visit` returns true, so an output_key marks that its step has run.
+func visitWizard(t *testing.T, content string, opts ...WizardOption) ([]string, map[string]interface{}, error) {
+	t.Helper()
+	visited := []string{}
+	opts = append(opts, WithActionCallback("visit", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+		visited = append(visited, args["id"].(string))
+		return true, nil
+	}))
+	w, err := LoadWizard(writeWizard(t, content), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// runSteps instead of Run, which needs a terminal for its program
+	state, err := w.runSteps(context.Background(), nil, nil)
+	return visited, state, err
+}
+
+func TestRunFollowsNextStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		wizard   string
+		expected []string
+	}{
+		{
+			name: "linear",
+			wizard: `
+steps:
+  - {id: a, type: action, action_type: function, function_name: visit, arguments: {id: a}, show_progress: false, show_completion: false}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false}
+`,
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "jump forward and end",
+			wizard: `
+steps:
+  - {id: a, type: action, action_type: function, function_name: visit, arguments: {id: a}, show_progress: false, show_completion: false, next_step: c}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false, next_step: end}
+  - {id: d, type: action, action_type: function, function_name: visit, arguments: {id: d}, show_progress: false, show_completion: false}
+`,
+			expected: []string{"a", "c"},
+		},
+		{
+			name: "jump back once",
+			wizard: `
+global_state: {looped: false}
+steps:
+  - {id: a, type: action, action_type: function, function_name: visit, arguments: {id: a}, show_progress: false, show_completion: false}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false, output_key: looped, skip_condition: looped, next_step: a}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false}
+`,
+			expected: []string{"a", "b", "a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		visited, _, err := visitWizard(t, tt.wizard)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(visited, tt.expected) {
+			t.Errorf("%s: expected the steps %v to run, got %v", tt.name, tt.expected, visited)
+		}
+	}
+}
+
+func TestDeclaredNextStep(t *testing.T) {
+	w := &Wizard{}
+	decision := &steps.DecisionStep{
+		BaseStep:    steps.BaseStep{StepID: "pick", StepType: "decision", NextStep: "after"},
+		TargetKey:   "choice",
+		Choices:     []string{"yes", "no", "maybe"},
+		NextStepMap: map[string]string{"yes": "accept", "no": "end"},
+	}
+	withDefault := &steps.DecisionStep{
+		BaseStep:    steps.BaseStep{StepID: "pick", StepType: "decision"},
+		TargetKey:   "choice",
+		NextStepMap: map[string]string{"yes": "accept", steps.NextStepMapDefault: "fallback"},
+	}
+	info := &steps.InfoStep{BaseStep: steps.BaseStep{StepID: "info", StepType: "info", NextStep: "summary"}}
+
+	tests := []struct {
+		name     string
+		step     steps.Step
+		state    map[string]interface{}
+		expected string
+	}{
+		{"mapped choice", decision, map[string]interface{}{"choice": "yes"}, "accept"},
+		{"mapped to end", decision, map[string]interface{}{"choice": "no"}, "end"},
+		{"unmapped choice falls back to next_step", decision, map[string]interface{}{"choice": "maybe"}, "after"},
+		{"no choice", decision, map[string]interface{}{}, "after"},
+		{"default entry", withDefault, map[string]interface{}{"choice": "no"}, "fallback"},
+		{"next_step", info, map[string]interface{}{}, "summary"},
+		{"following step", &steps.InfoStep{BaseStep: steps.BaseStep{StepID: "x", StepType: "info"}}, nil, ""},
+	}
+	for _, tt := range tests {
+		if got := w.declaredNextStep(tt.step, tt.state); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRunOnErrorGoto(t *testing.T) {
+	visited, state, err := visitWizard(t, `
+steps:
+  - {id: a, type: action, action_type: function, function_name: fail, show_progress: false, show_completion: false, on_error: {then: goto, goto: c}}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false}
+  - {id: c, type: action, action_type: function, function_name: visit, arguments: {id: c}, show_progress: false, show_completion: false}
+`, WithActionCallback("fail", func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("unreachable")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(visited, []string{"c"}) {
+		t.Errorf("expected only c to run, got %v", visited)
+	}
+	if _, ok := state["a_error"]; !ok {
+		t.Errorf("expected the error of a in the state, got %v", state)
+	}
+}
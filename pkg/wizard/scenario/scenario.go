@@ -0,0 +1,352 @@
+// Package scenario runs wizards headless against scripted scenarios and checks
+// the path they take, the state they end with, the error they return and the
+// views they render. It backs the `uhoh test` command.
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/wizard"
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// File is a scenario file: the wizard under test and the scenarios to run it
+// with. Relative paths are resolved from the directory of the scenario file.
+type File struct {
+	Wizard    string      `yaml:"wizard"`
+	Scenarios []*Scenario `yaml:"scenarios"`
+
+	path string
+}
+
+// Scenario is one scripted run of the wizard.
+type Scenario struct {
+	Name         string                   `yaml:"name"`
+	InitialState map[string]interface{}   `yaml:"initial_state,omitempty"`
+	Answers      map[string]interface{}   `yaml:"answers,omitempty"` // step ID -> field key -> answer
+	Callbacks    map[string]*MockCallback `yaml:"callbacks,omitempty"`
+	Steps        map[string]*MockStep     `yaml:"steps,omitempty"` // step ID -> mock
+	Expect       Expectations             `yaml:"expect"`
+	// Golden is a file holding the views rendered by the run, compared
+	// with the views of every run (and rewritten when updating).
+	Golden string `yaml:"golden,omitempty"`
+}
+
+// MockCallback replaces a callback of the wizard. It is registered both as
+// an action callback (function_name) and as a before, after, validation or
+// navigation callback.
+type MockCallback struct {
+	// Result is returned by the action callback.
+	Result interface{} `yaml:"result,omitempty"`
+	// NextStep is returned by the navigation callback.
+	NextStep string `yaml:"next_step,omitempty"`
+	// Error makes the callback fail with this message.
+	Error string `yaml:"error,omitempty"`
+}
+
+// MockStep replaces a step of the wizard by ID, so that shell and HTTP
+// actions do not run. The before and after callbacks of the step still run.
+type MockStep struct {
+	// Result is stored under the output_key of the step.
+	Result interface{} `yaml:"result,omitempty"`
+	// State is merged into the state, like the keys of a response_mapping.
+	State map[string]interface{} `yaml:"state,omitempty"`
+	// Error makes the step fail with this message.
+	Error string `yaml:"error,omitempty"`
+}
+
+// Expectations are checked after the run. Unset expectations are not
+// checked, except that a run without an expected error must succeed.
+type Expectations struct {
+	// Path lists the IDs of the steps that ran, in order.
+	Path []string `yaml:"path,omitempty"`
+	// State holds keys the final state must have, with their values.
+	State map[string]interface{} `yaml:"state,omitempty"`
+	// Error is a substring of the error the run must fail with.
+	Error string `yaml:"error,omitempty"`
+}
+
+// Result is the outcome of one scenario.
+type Result struct {
+	Scenario *Scenario
+	Path     []string
+	State    map[string]interface{}
+	Err      error
+	Views    string
+	// Failures lists the expectations that were not met.
+	Failures []string
+}
+
+// Passed reports whether the run met every expectation.
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Load reads a scenario file. Unknown keys are an error, so that a typo does
+// not silently disable an expectation.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read scenario file %s", path)
+	}
+	f := &File{path: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(f); err != nil {
+		return nil, errors.Wrapf(err, "could not parse scenario file %s", path)
+	}
+	if f.Wizard == "" {
+		return nil, errors.Errorf("scenario file %s: wizard is required", path)
+	}
+	if len(f.Scenarios) == 0 {
+		return nil, errors.Errorf("scenario file %s has no scenarios", path)
+	}
+	for i, s := range f.Scenarios {
+		if s == nil {
+			return nil, errors.Errorf("scenario file %s: scenario %d is empty", path, i)
+		}
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("scenario %d", i+1)
+		}
+	}
+	return f, nil
+}
+
+// resolve returns path relative to the directory of the scenario file.
+func (f *File) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(f.path), path)
+}
+
+// Run runs every scenario of the file. If update is set, golden files are
+// rewritten with the views of the run instead of being compared. An error is
+// returned only if a scenario could not be run at all, for example because
+// the wizard does not load.
+func (f *File) Run(ctx context.Context, update bool) ([]*Result, error) {
+	results := make([]*Result, 0, len(f.Scenarios))
+	for _, s := range f.Scenarios {
+		res, err := f.runScenario(ctx, s, update)
+		if err != nil {
+			return results, errors.Wrapf(err, "scenario %q", s.Name)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (f *File) runScenario(ctx context.Context, s *Scenario, update bool) (*Result, error) {
+	res := &Result{Scenario: s}
+
+	var views strings.Builder
+	currentStep := ""
+	answers := s.Answers
+	if answers == nil {
+		answers = map[string]interface{}{}
+	}
+	opts := []wizard.WizardOption{
+		wizard.WithInitialState(s.InitialState),
+		wizard.WithScriptedAnswers(answers),
+		wizard.WithStepObserver(func(stepID string, _ map[string]interface{}) {
+			res.Path = append(res.Path, stepID)
+			currentStep = stepID
+		}),
+	}
+	for name, mock := range s.Callbacks {
+		if mock == nil {
+			mock = &MockCallback{}
+		}
+		opts = append(opts,
+			wizard.WithActionCallback(name, mock.actionCallback()),
+			wizard.WithCallback(name, mock.callback()))
+	}
+	for stepID, mock := range s.Steps {
+		if mock == nil {
+			mock = &MockStep{}
+		}
+		opts = append(opts, wizard.WithStepMock(stepID, mock.execute))
+	}
+
+	wz, err := wizard.LoadWizard(f.resolve(f.Wizard), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = pkg.WithViewRecorder(ctx, func(view string) {
+		fmt.Fprintf(&views, "=== %s\n%s\n\n", currentStep, strings.TrimRight(view, "\n "))
+	})
+	res.State, res.Err = wz.Run(ctx, nil)
+	res.Views = views.String()
+
+	res.check(s.Expect)
+	if s.Golden != "" {
+		if err := res.checkGolden(f.resolve(s.Golden), update); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (m *MockCallback) actionCallback() wizard.ActionCallbackFunc {
+	return func(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+		if m.Error != "" {
+			return nil, errors.New(m.Error)
+		}
+		return m.Result, nil
+	}
+}
+
+func (m *MockCallback) callback() wizard.WizardCallbackFunc {
+	return func(ctx context.Context, state map[string]interface{}) (interface{}, *string, error) {
+		if m.Error != "" {
+			return nil, nil, errors.New(m.Error)
+		}
+		if m.NextStep != "" {
+			next := m.NextStep
+			return m.Result, &next, nil
+		}
+		return m.Result, nil, nil
+	}
+}
+
+func (m *MockStep) execute(ctx context.Context, step steps.Step, state map[string]interface{}) (map[string]interface{}, error) {
+	if m.Error != "" {
+		return nil, errors.New(m.Error)
+	}
+	result := map[string]interface{}{}
+	for k, v := range m.State {
+		result[k] = v
+	}
+	if m.Result != nil {
+		action, ok := step.(*steps.ActionStep)
+		if !ok || action.OutputKey == "" {
+			return nil, errors.Errorf("step %s has no output_key to store the mocked result in", step.ID())
+		}
+		result[action.OutputKey] = m.Result
+	}
+	return result, nil
+}
+
+// check records the expectations the run did not meet.
+func (r *Result) check(expect Expectations) {
+	if expect.Path != nil && !slices.Equal(expect.Path, r.Path) {
+		r.fail("path: expected %s, got %s", formatPath(expect.Path), formatPath(r.Path))
+	}
+
+	switch {
+	case expect.Error == "" && r.Err != nil:
+		r.fail("unexpected error: %v", r.Err)
+	case expect.Error != "" && r.Err == nil:
+		r.fail("expected an error containing %q, got none", expect.Error)
+	case expect.Error != "" && !strings.Contains(r.Err.Error(), expect.Error):
+		r.fail("expected an error containing %q, got: %v", expect.Error, r.Err)
+	}
+
+	keys := make([]string, 0, len(expect.State))
+	for k := range expect.State {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		want := expect.State[k]
+		got, ok := r.State[k]
+		if !ok {
+			r.fail("state.%s: expected %v, but it is not set", k, want)
+			continue
+		}
+		if !sameValue(want, got) {
+			r.fail("state.%s: expected %v, got %v", k, want, got)
+		}
+	}
+}
+
+// checkGolden compares the recorded views with the golden file, or writes
+// them to it when updating.
+func (r *Result) checkGolden(path string, update bool) error {
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return errors.Wrapf(err, "could not create directory for golden file %s", path)
+		}
+		if err := os.WriteFile(path, []byte(r.Views), 0o644); err != nil {
+			return errors.Wrapf(err, "could not write golden file %s", path)
+		}
+		return nil
+	}
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		r.fail("golden file %s does not exist; run with --update to create it", path)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not read golden file %s", path)
+	}
+	if diff := firstDifference(string(expected), r.Views); diff != "" {
+		r.fail("views differ from golden file %s (run with --update to accept):\n%s", path, diff)
+	}
+	return nil
+}
+
+func (r *Result) fail(format string, args ...interface{}) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+}
+
+// sameValue compares an expected value from the scenario file with a value
+// of the final state. Both go through YAML first, so an int64 from an integer
+// field equals a YAML integer and a []string equals a YAML list.
+func sameValue(want, got interface{}) bool {
+	return reflect.DeepEqual(normalize(want), normalize(got))
+}
+
+func normalize(v interface{}) interface{} {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var ret interface{}
+	if err := yaml.Unmarshal(data, &ret); err != nil {
+		return v
+	}
+	return ret
+}
+
+// firstDifference describes the first line where got differs from
+// expected, or returns "" if they are equal.
+func firstDifference(expected, got string) string {
+	if expected == got {
+		return ""
+	}
+	expectedLines := strings.Split(expected, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(expectedLines) || i < len(gotLines); i++ {
+		var e, g string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if e != g || i >= len(expectedLines) || i >= len(gotLines) {
+			return fmt.Sprintf("  line %d:\n  - %s\n  + %s", i+1, e, g)
+		}
+	}
+	return ""
+}
+
+func formatPath(path []string) string {
+	if len(path) == 0 {
+		return "(no steps)"
+	}
+	return strings.Join(path, " -> ")
+}
@@ -0,0 +1,62 @@
+package scenario
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// TestExampleScenarios runs the scenario files of the example wizards,
+// including their golden files.
+func TestExampleScenarios(t *testing.T) {
+	paths, err := filepath.Glob("../../../cmd/uhoh/examples/wizard/*.scenarios.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no example scenario files found")
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			f, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := f.Run(context.Background(), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, res := range results {
+				for _, failure := range res.Failures {
+					t.Errorf("%s: %s", res.Scenario.Name, failure)
+				}
+			}
+		})
+	}
+}
+
+func TestMockStepResult(t *testing.T) {
+	f := &File{
+		Wizard: "../../../cmd/uhoh/examples/wizard/http-action.yaml",
+		Scenarios: []*Scenario{{
+			Name:    "result without output_key",
+			Answers: map[string]interface{}{"user-info": map[string]interface{}{"github_username": "octocat"}},
+			Steps:   map[string]*MockStep{"fetch-repos": {Result: []interface{}{}}},
+			Expect:  Expectations{Error: "step fetch-repos has no output_key"},
+		}, {
+			Name:   "unknown step",
+			Steps:  map[string]*MockStep{"does-not-exist": {}},
+			Expect: Expectations{Error: "mocks given for unknown steps: does-not-exist"},
+		}},
+		path: "scenario.yaml",
+	}
+	results, err := f.Run(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range results {
+		if !res.Passed() {
+			t.Errorf("%s: %v", res.Scenario.Name, res.Failures)
+		}
+	}
+}
@@ -1,7 +1,14 @@
 package shellcmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
 
 	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
 )
@@ -30,3 +37,56 @@ func RunActionCallback(ctx context.Context, cmdStr string, opts Options) (interf
 
 	return callbackResult, nil
 }
+
+// Map returns the result as stored in the wizard state by shell actions:
+// command, exit_code, output, duration (in seconds), started_at, ended_at and
+// error (empty if the command succeeded).
+func (r *Result) Map() map[string]interface{} {
+	errString := ""
+	if r.Err != nil {
+		errString = r.Err.Error()
+	}
+	return map[string]interface{}{
+		"command":    r.Cmd,
+		"exit_code":  r.ExitCode,
+		"output":     r.Output,
+		"duration":   r.Duration.Seconds(),
+		"started_at": r.StartedAt,
+		"ended_at":   r.EndedAt,
+		"error":      errString,
+	}
+}
+
+// runHeadless runs the command without the viewer. Its output is copied to
+// stderr as it comes and captured in the result.
+func runHeadless(ctx context.Context, cmdStr string, opts Options) *Result {
+	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
+	if opts.WorkDir != "" {
+		cmd.Dir = opts.WorkDir
+	}
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+
+	var output bytes.Buffer
+	out := io.MultiWriter(&output, os.Stderr)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	res := &Result{Cmd: cmdStr, StartedAt: time.Now()}
+	err := cmd.Run()
+	res.EndedAt = time.Now()
+	res.Duration = res.EndedAt.Sub(res.StartedAt)
+	res.Output = output.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+		res.Err = fmt.Errorf("command exited with code %d", res.ExitCode)
+	default:
+		res.Err = err
+	}
+	return res
+}
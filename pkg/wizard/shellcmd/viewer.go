@@ -16,6 +16,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/go-go-golems/uhoh/pkg"
 )
 
 type streamKind int
@@ -80,8 +81,24 @@ type commandStartFailedMsg struct {
 type tickMsg struct{}
 
 // Run launches the Bubble Tea viewer and blocks until the command completes or the program exits.
+// If ctx carries a pkg.Host (as it does inside a wizard), the viewer is shown there instead of
+// in a program of its own. In a headless context (see pkg.WithAnswers) the command runs without
+// a viewer and its output goes to stderr.
 func Run(ctx context.Context, cmdStr string, opts Options) (*Result, error) {
+	if pkg.IsHeadless(ctx) {
+		return runHeadless(ctx, cmdStr, opts), nil
+	}
+
 	m := newModel(ctx, cmdStr, opts)
+
+	if host := pkg.HostFrom(ctx); host != nil {
+		m.quit = pkg.ModelDone
+		if _, err := host.RunModel(ctx, m); err != nil {
+			return nil, err
+		}
+		return m.result(), nil
+	}
+
 	programOpts := []tea.ProgramOption{tea.WithAltScreen()}
 	if ctx != nil {
 		programOpts = append(programOpts, tea.WithContext(ctx))
@@ -101,23 +118,28 @@ func Run(ctx context.Context, cmdStr string, opts Options) (*Result, error) {
 		return nil, fmt.Errorf("unexpected final model type %T", finalModel)
 	}
 
+	return vm.result(), nil
+}
+
+// result returns the outcome of the command once the viewer is done.
+func (m *model) result() *Result {
 	res := &Result{
-		Cmd:       cmdStr,
-		ExitCode:  vm.exitCode,
-		Output:    vm.plainBuffer.String(),
-		Duration:  vm.endedAt.Sub(vm.startedAt),
-		Err:       vm.err,
-		StartedAt: vm.startedAt,
-		EndedAt:   vm.endedAt,
+		Cmd:       m.cmdStr,
+		ExitCode:  m.exitCode,
+		Output:    m.plainBuffer.String(),
+		Duration:  m.endedAt.Sub(m.startedAt),
+		Err:       m.err,
+		StartedAt: m.startedAt,
+		EndedAt:   m.endedAt,
 	}
 
-	if vm.status == statusSucceeded {
+	if m.status == statusSucceeded {
 		res.Err = nil
-	} else if vm.err == nil && vm.exitCode != 0 {
-		res.Err = fmt.Errorf("command exited with code %d", vm.exitCode)
+	} else if m.err == nil && m.exitCode != 0 {
+		res.Err = fmt.Errorf("command exited with code %d", m.exitCode)
 	}
 
-	return res, nil
+	return res
 }
 
 type model struct {
@@ -150,6 +172,9 @@ type model struct {
 
 	keepOpen bool
 	title    string
+
+	// quit ends the viewer: tea.Quit, or pkg.ModelDone when hosted.
+	quit tea.Cmd
 }
 
 func newModel(ctx context.Context, cmdStr string, opts Options) *model {
@@ -167,6 +192,7 @@ func newModel(ctx context.Context, cmdStr string, opts Options) *model {
 		status:   statusInit,
 		keepOpen: opts.KeepOpen,
 		title:    opts.Title,
+		quit:     tea.Quit,
 	}
 }
 
@@ -186,7 +212,7 @@ func (m *model) startCommand() error {
 	m.status = statusRunning
 	m.startedAt = time.Now()
 
-	cmd := exec.CommandContext(m.ctx, "bash", "-lc", m.cmdStr)
+	cmd := exec.CommandContext(m.ctx, "bash", "-c", m.cmdStr)
 	if m.opts.WorkDir != "" {
 		cmd.Dir = m.opts.WorkDir
 	}
@@ -220,7 +246,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = msg.err
 		m.exitCode = exitStatusFromError(msg.err)
 		m.endedAt = time.Now()
-		return m, tea.Quit
+		return m, m.quit
 
 	case appendOutputMsg:
 		m.appendOutput(msg)
@@ -270,7 +296,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.endedAt = time.Now()
 		if !m.keepOpen {
-			return m, tea.Quit
+			return m, m.quit
 		}
 		return m, nil
 
@@ -302,7 +328,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			switch msg.String() {
 			case "q", "enter", "esc", "ctrl+c":
-				return m, tea.Quit
+				return m, m.quit
 			}
 		}
 		return m, nil
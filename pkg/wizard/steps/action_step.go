@@ -3,9 +3,11 @@ package steps
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -23,12 +25,28 @@ type ActionCallbackRegistry interface {
 // ActionStep represents a step that performs a backend action.
 type ActionStep struct {
 	BaseStep     `yaml:",inline"`
-	ActionType   string                 `yaml:"action_type"`             // e.g., "function", "api_call"
+	ActionType   string                 `yaml:"action_type"`             // "function", "shell" or "http"
 	FunctionName string                 `yaml:"function_name,omitempty"` // For action_type: function
 	Arguments    map[string]interface{} `yaml:"arguments,omitempty"`
-	OutputKey    string                 `yaml:"output_key,omitempty"`
-	ShowProgress *bool                  `yaml:"show_progress,omitempty"`
-	ShowComplete *bool                  `yaml:"show_completion,omitempty"`
+	Command      string                 `yaml:"command,omitempty"`   // For action_type: shell
+	WorkDir      string                 `yaml:"workdir,omitempty"`   // For action_type: shell
+	Env          map[string]string      `yaml:"env,omitempty"`       // For action_type: shell
+	KeepOpen     bool                   `yaml:"keep_open,omitempty"` // For action_type: shell
+
+	// For action_type: http
+	Method          string            `yaml:"method,omitempty"`
+	URL             string            `yaml:"url,omitempty"`
+	Headers         map[string]string `yaml:"headers,omitempty"`
+	Body            interface{}       `yaml:"body,omitempty"`
+	RequestTimeout  string            `yaml:"request_timeout,omitempty"` // e.g. "10s", defaults to 30s
+	ExpectStatus    []int             `yaml:"expect_status,omitempty"`
+	ResponseMapping map[string]string `yaml:"response_mapping,omitempty"` // state key -> JSON path
+
+	OutputKey    string `yaml:"output_key,omitempty"`
+	ShowProgress *bool  `yaml:"show_progress,omitempty"`
+	ShowComplete *bool  `yaml:"show_completion,omitempty"`
+	// OnError handles failures of the action instead of aborting the wizard.
+	OnError *ErrorPolicy `yaml:"on_error,omitempty"`
 
 	// Non-YAML fields
 	registry ActionCallbackRegistry // Registry for action callbacks
@@ -53,10 +71,23 @@ func (as *ActionStep) SetCallbackRegistry(registry ActionCallbackRegistry) {
 func (as *ActionStep) Execute(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
 	log.Debug().Str("stepId", as.ID()).Msgf("--- Step: %s ---", as.Title())
 
+	if as.OnError != nil {
+		return as.executeWithPolicy(ctx, state)
+	}
+	return as.executeAction(ctx, state)
+}
+
+// executeAction runs the action once.
+func (as *ActionStep) executeAction(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
 	stepResult := map[string]interface{}{}
 
-	// Currently only support "function" type actions
-	if as.ActionType != "function" {
+	switch as.ActionType {
+	case "function":
+	case "shell":
+		return as.executeShell(ctx, state)
+	case "http":
+		return as.executeHTTP(ctx, state)
+	default:
 		return nil, errors.Errorf("unsupported action type: %s", as.ActionType)
 	}
 
@@ -64,21 +95,23 @@ func (as *ActionStep) Execute(ctx context.Context, state map[string]interface{})
 		return nil, errors.New("function name not specified for function-type action")
 	}
 
+	title, _, err := as.renderHeader(state)
+	if err != nil {
+		return nil, err
+	}
+	arguments, err := as.renderArguments(state)
+	if err != nil {
+		return nil, err
+	}
+
 	showProgress := boolValue(as.ShowProgress, true)
 	showCompletion := boolValue(as.ShowComplete, true)
 
+	stopProgress := func() {}
 	if showProgress {
-		actionNote := huh.NewNote().
-			Title(as.Title()).
-			Description(fmt.Sprintf("Executing action: %s\n\nPlease wait...", as.FunctionName))
-
-		go func() {
-			_ = actionNote.Run()
-		}()
-
-		// Small delay to ensure note is visible before the callback potentially runs its own UI.
-		time.Sleep(100 * time.Millisecond)
+		stopProgress = as.startProgress(ctx, title, as.FunctionName)
 	}
+	defer stopProgress()
 
 	var (
 		actionResult interface{}
@@ -89,9 +122,9 @@ func (as *ActionStep) Execute(ctx context.Context, state map[string]interface{})
 	// Execute the function via the registry if available
 	if as.registry != nil {
 		log.Debug().Str("stepId", as.ID()).Str("function", as.FunctionName).
-			Interface("arguments", as.Arguments).Msg("Executing function via registry")
+			Interface("arguments", arguments).Msg("Executing function via registry")
 
-		rawResult, err := as.registry.ExecuteActionCallback(ctx, as.FunctionName, state, as.Arguments)
+		rawResult, err := as.registry.ExecuteActionCallback(ctx, as.FunctionName, state, arguments)
 		actionResult, uiHandled = interpretActionResult(rawResult)
 		actionErr = err
 		if actionErr != nil {
@@ -114,6 +147,8 @@ func (as *ActionStep) Execute(ctx context.Context, state map[string]interface{})
 		log.Warn().Str("stepId", as.ID()).Msg("Using simulated action result - no callback registry provided")
 	}
 
+	stopProgress()
+
 	// If we have an output key, store the result
 	if as.OutputKey != "" && actionResult != nil {
 		stepResult[as.OutputKey] = actionResult
@@ -122,27 +157,120 @@ func (as *ActionStep) Execute(ctx context.Context, state map[string]interface{})
 	}
 
 	if showCompletion && !uiHandled {
-		// Show a confirmation message after the action completes
-		confirmation := huh.NewNote().
-			Title("Action Complete").
-			Description(fmt.Sprintf("Action '%s' completed successfully.", as.FunctionName))
-
-		err := confirmation.Run()
-		if err != nil {
-			if errors.Is(err, huh.ErrUserAborted) {
-				return nil, ErrUserAborted
-			}
-			return nil, errors.Wrap(err, "error showing completion message")
+		if err := as.showCompletionNote(ctx, fmt.Sprintf("Action '%s' completed successfully.", as.FunctionName)); err != nil {
+			return nil, err
 		}
 	}
 
 	return stepResult, nil
 }
 
+// startProgress shows that action is running until the returned stop is
+// called (stop may be called more than once). Inside a wizard program it is a spinner in the wizard layout;
+// in a headless run it is only logged; otherwise a note is printed.
+func (as *ActionStep) startProgress(ctx context.Context, title string, action string) (stop func()) {
+	if pkg.IsHeadless(ctx) {
+		log.Info().Str("stepId", as.ID()).Str("action", action).Msg("Executing action")
+		return func() {}
+	}
+	description := fmt.Sprintf("Executing action: %s\n\nPlease wait...", action)
+	if host := pkg.HostFrom(ctx); host != nil {
+		return host.StartProgress(title, description)
+	}
+
+	actionNote := huh.NewNote().
+		Title(title).
+		Description(description)
+
+	// The note runs in a program of its own, which stop cancels and waits
+	// for, so the terminal is restored before the next form is shown
+	noteCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = huh.NewForm(huh.NewGroup(actionNote)).RunWithContext(noteCtx)
+	}()
+
+	// Small delay to ensure note is visible before the callback potentially runs its own UI.
+	time.Sleep(100 * time.Millisecond)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// showCompletionNote shows a confirmation message after the action completes.
+func (as *ActionStep) showCompletionNote(ctx context.Context, message string) error {
+	confirmation := huh.NewNote().
+		Title("Action Complete").
+		Description(message)
+
+	err := pkg.RunHuhForm(ctx, huh.NewForm(huh.NewGroup(confirmation)))
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return ErrUserAborted
+		}
+		if errors.Is(err, ErrGoBack) {
+			return err
+		}
+		return errors.Wrap(err, "error showing completion message")
+	}
+	return nil
+}
+
+// Validate checks that the fields required by the action type are set and
+// that the shell command and http request are valid templates.
+func (as *ActionStep) Validate() error {
+	if err := as.BaseStep.Validate(); err != nil {
+		return err
+	}
+	switch as.ActionType {
+	case "function":
+		if as.FunctionName == "" {
+			return errors.Errorf("step %s: function_name is required for action_type function", as.ID())
+		}
+	case "shell":
+		if as.Command == "" {
+			return errors.Errorf("step %s: command is required for action_type shell", as.ID())
+		}
+		if _, err := pkg.ParseTemplate(as.ID(), as.Command); err != nil {
+			return errors.Wrapf(err, "invalid command template in step %s", as.ID())
+		}
+	case "http":
+		if err := as.validateHTTP(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("step %s: unsupported action type: %s", as.ID(), as.ActionType)
+	}
+	if as.OnError != nil {
+		return as.OnError.validate(as.ID())
+	}
+	return nil
+}
+
 func (as *ActionStep) GetBaseStep() *BaseStep {
 	return &as.BaseStep
 }
 
+// renderArguments interpolates every string in the action arguments against
+// the wizard state. The step's own Arguments are left untouched so the step can
+// run again with a different state.
+func (as *ActionStep) renderArguments(state map[string]interface{}) (map[string]interface{}, error) {
+	if as.Arguments == nil {
+		return nil, nil
+	}
+	rendered, err := pkg.InterpolateValue(as.Arguments, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not render arguments of step %s", as.ID())
+	}
+	return rendered.(map[string]interface{}), nil
+}
+
 func interpretActionResult(result interface{}) (interface{}, bool) {
 	if result == nil {
 		return nil, false
@@ -2,12 +2,20 @@ package steps
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
+// NextStepMapDefault is the next_step_map key used for choices without an
+// entry of their own.
+const NextStepMapDefault = "default"
+
 // DecisionStep represents a step where the user makes a choice.
 type DecisionStep struct {
 	BaseStep    `yaml:",inline"`
@@ -26,51 +34,89 @@ func (ds *DecisionStep) Execute(ctx context.Context, state map[string]interface{
 		return nil, errors.New("decision step has no choices defined")
 	}
 
+	title, description, err := ds.renderHeader(state)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create options for the select field
 	options := []huh.Option[string]{}
 	for _, choice := range ds.Choices {
 		options = append(options, huh.NewOption[string](choice, choice))
 	}
 
-	// Initialize the chosen value
-	var chosenValue string
+	// Initialize the chosen value, preselecting the previous answer when the
+	// user comes back to this step
+	chosenValue, _ := state[ds.TargetKey].(string)
 
 	// Display the title and description
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
-				Title(ds.Title()).
-				Description(ds.Description()).
+				Title(title).
+				Description(description).
 				Options(options...).
 				Value(&chosenValue),
 		),
 	)
 
 	// Run the form
-	err := form.Run()
+	err = pkg.RunHuhForm(ctx, form)
 	if err != nil {
 		if errors.Is(err, huh.ErrUserAborted) {
 			return nil, ErrUserAborted
 		}
+		if errors.Is(err, ErrGoBack) {
+			return nil, err
+		}
 		return nil, errors.Wrap(err, "error running decision form")
 	}
 
+	// In a headless run the form was not shown; the choice is scripted
+	if answers, ok := pkg.AnswersFrom(ctx); ok {
+		chosenValue, err = ds.answerChoice(answers, state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Store the result in the state
 	stepResult := map[string]interface{}{
 		ds.TargetKey: chosenValue,
 	}
 
-	// Set the next step if specified in the map
-	if ds.NextStepMap != nil {
-		if nextStep, ok := ds.NextStepMap[chosenValue]; ok {
-			// Store the next step in the base step
-			ds.NextStep = nextStep
-			log.Debug().Str("stepId", ds.ID()).Str("choice", chosenValue).Str("nextStep", nextStep).Msg("Next step determined from next_step_map")
+	log.Debug().Str("stepId", ds.ID()).Str("targetKey", ds.TargetKey).Str("value", chosenValue).Msg("Decision made")
+	return stepResult, nil
+}
+
+// answerChoice returns the scripted answer for the decision, stored under
+// target_key. Without an answer, a valid choice already in the state is kept.
+func (ds *DecisionStep) answerChoice(answers map[string]interface{}, state map[string]interface{}) (string, error) {
+	answer, ok := answers[ds.TargetKey]
+	if !ok {
+		if current, ok := state[ds.TargetKey].(string); ok && slices.Contains(ds.Choices, current) {
+			return current, nil
 		}
+		return "", errors.Errorf("missing answer for %s: expected one of %s", ds.TargetKey, strings.Join(ds.Choices, ", "))
 	}
+	choice := fmt.Sprintf("%v", answer)
+	if !slices.Contains(ds.Choices, choice) {
+		return "", errors.Errorf("invalid answer for %s: %s is not one of %s", ds.TargetKey, choice, strings.Join(ds.Choices, ", "))
+	}
+	return choice, nil
+}
 
-	log.Debug().Str("stepId", ds.ID()).Str("targetKey", ds.TargetKey).Str("value", chosenValue).Msg("Decision made")
-	return stepResult, nil
+// NextStepFor returns the step to go to after choice was made: the
+// next_step_map entry for the choice, else its `default` entry, else the
+// step's next_step. An empty result means the following step in the file.
+func (ds *DecisionStep) NextStepFor(choice string) string {
+	if next, ok := ds.NextStepMap[choice]; ok {
+		return next
+	}
+	if next, ok := ds.NextStepMap[NextStepMapDefault]; ok {
+		return next
+	}
+	return ds.NextStep
 }
 
 func (ds *DecisionStep) GetBaseStep() *BaseStep {
@@ -0,0 +1,38 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+)
+
+// EditRequest is returned as the error of a step (the editable summary) to
+// change the answer stored under Key. The runner re-runs the step that set
+// Key, pre-filled with the current values, and then walks forward again.
+type EditRequest struct {
+	Key string
+}
+
+func (e *EditRequest) Error() string {
+	return fmt.Sprintf("edit request for %s", e.Key)
+}
+
+// FieldSource identifies the step that set a state key.
+type FieldSource struct {
+	StepID    string
+	StepTitle string
+}
+
+type fieldSourcesKey struct{}
+
+// WithFieldSources returns a context that carries, for each state key, the
+// step that set it. The runner passes it to every step it executes.
+func WithFieldSources(ctx context.Context, sources map[string]FieldSource) context.Context {
+	return context.WithValue(ctx, fieldSourcesKey{}, sources)
+}
+
+// FieldSources returns the field sources stored in ctx by WithFieldSources, or
+// nil.
+func FieldSources(ctx context.Context) map[string]FieldSource {
+	sources, _ := ctx.Value(fieldSourcesKey{}).(map[string]FieldSource)
+	return sources
+}
@@ -0,0 +1,212 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// What an action step does once its retries are exhausted.
+const (
+	OnErrorFail   = "fail"
+	OnErrorSkip   = "skip"
+	OnErrorGoto   = "goto"
+	OnErrorPrompt = "prompt"
+)
+
+// defaultBackoff is the delay before the first retry.
+const defaultBackoff = time.Second
+
+// ErrorPolicy configures how an action step handles a failing action.
+//
+// The action is retried up to Retries times, waiting Backoff before the first
+// retry and doubling the delay each time. If it still fails, Then decides what
+// happens: fail aborts the wizard (the default), skip continues with Default
+// stored in output_key, goto continues at the step Goto, and prompt shows the
+// error and lets the user retry, skip or abort.
+//
+// When the wizard continues after a failure, the error message and the number
+// of attempts are stored in the state under ErrorKey.
+type ErrorPolicy struct {
+	Retries  int         `yaml:"retries,omitempty"`
+	Backoff  string      `yaml:"backoff,omitempty"` // e.g. "500ms", defaults to 1s
+	Then     string      `yaml:"then,omitempty"`
+	Default  interface{} `yaml:"default,omitempty"`
+	Goto     string      `yaml:"goto,omitempty"`
+	ErrorKey string      `yaml:"error_key,omitempty"` // defaults to <step id>_error
+}
+
+// JumpRequest is returned as the error of a step that asks the runner to
+// continue at StepID, with Result merged into the state as the step's result.
+type JumpRequest struct {
+	StepID string
+	Result map[string]interface{}
+	Err    error
+}
+
+func (j *JumpRequest) Error() string {
+	return fmt.Sprintf("jump to %s after error: %v", j.StepID, j.Err)
+}
+
+func (j *JumpRequest) Unwrap() error {
+	return j.Err
+}
+
+func (p *ErrorPolicy) validate(stepID string) error {
+	if p.Retries < 0 {
+		return errors.Errorf("step %s: on_error retries must not be negative", stepID)
+	}
+	if p.Backoff != "" {
+		if _, err := time.ParseDuration(p.Backoff); err != nil {
+			return errors.Wrapf(err, "invalid on_error backoff in step %s", stepID)
+		}
+	}
+	switch p.Then {
+	case "", OnErrorFail, OnErrorSkip, OnErrorPrompt:
+	case OnErrorGoto:
+		if p.Goto == "" {
+			return errors.Errorf("step %s: on_error goto needs a goto step ID", stepID)
+		}
+	default:
+		return errors.Errorf("step %s: unknown on_error then '%s' (expected %s, %s, %s or %s)",
+			stepID, p.Then, OnErrorFail, OnErrorSkip, OnErrorGoto, OnErrorPrompt)
+	}
+	return nil
+}
+
+// errorKey returns the state key the failure of step is recorded under.
+func (p *ErrorPolicy) errorKey(stepID string) string {
+	if p.ErrorKey != "" {
+		return p.ErrorKey
+	}
+	return stepID + "_error"
+}
+
+// executeWithPolicy runs the action, handling failures according to
+// OnError.
+func (as *ActionStep) executeWithPolicy(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
+	policy := as.OnError
+	backoff := defaultBackoff
+	if policy.Backoff != "" {
+		var err error
+		backoff, err = time.ParseDuration(policy.Backoff)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid on_error backoff in step %s", as.ID())
+		}
+	}
+
+	attempts := 0
+	retriesLeft := policy.Retries
+	for {
+		attempts++
+		result, err := as.executeAction(ctx, state)
+		if err == nil || errors.Is(err, ErrUserAborted) || errors.Is(err, ErrGoBack) {
+			return result, err
+		}
+		log.Warn().Err(err).Str("stepId", as.ID()).Int("attempt", attempts).Msg("Action failed")
+
+		if retriesLeft > 0 {
+			retriesLeft--
+			log.Debug().Str("stepId", as.ID()).Dur("backoff", backoff).Msg("Retrying action")
+			select {
+			case <-ctx.Done():
+				return result, errors.Wrapf(ctx.Err(), "step %s: gave up retrying", as.ID())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			continue
+		}
+
+		if result == nil {
+			result = map[string]interface{}{}
+		}
+		result[policy.errorKey(as.ID())] = map[string]interface{}{
+			"message":  err.Error(),
+			"attempts": attempts,
+		}
+
+		switch policy.Then {
+		case OnErrorSkip:
+			return as.skipResult(result), nil
+		case OnErrorGoto:
+			return nil, &JumpRequest{StepID: policy.Goto, Result: result, Err: err}
+		case OnErrorPrompt:
+			choice, promptErr := as.promptOnError(ctx, state, err, attempts)
+			if promptErr != nil {
+				return nil, promptErr
+			}
+			switch choice {
+			case OnErrorSkip:
+				return as.skipResult(result), nil
+			case "retry":
+				continue
+			default:
+				return nil, ErrUserAborted
+			}
+		default:
+			return result, errors.Wrapf(err, "action failed after %d attempt(s)", attempts)
+		}
+	}
+}
+
+// OnErrorAnswerKey is the answer of an action step that replies to its
+// on_error prompt in a headless run.
+const OnErrorAnswerKey = "on_error"
+
+// skipResult stores the policy's default in output_key, if both are set.
+func (as *ActionStep) skipResult(result map[string]interface{}) map[string]interface{} {
+	if as.OutputKey != "" && as.OnError.Default != nil {
+		result[as.OutputKey] = as.OnError.Default
+	}
+	return result
+}
+
+// promptOnError shows the error and asks whether to retry, skip or abort. In
+// a headless run, the answer is read from the step's `on_error` answer, which
+// can be skip or abort: retrying with the same answer would never end.
+func (as *ActionStep) promptOnError(ctx context.Context, state map[string]interface{}, actionErr error, attempts int) (string, error) {
+	title, _, err := as.renderHeader(state)
+	if err != nil || title == "" {
+		title = as.ID()
+	}
+
+	choice := "retry"
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(fmt.Sprintf("%s failed", title)).
+				Description(fmt.Sprintf("%v\n\n(attempt %d)", actionErr, attempts)).
+				Options(
+					huh.NewOption("Retry", "retry"),
+					huh.NewOption("Skip this step", OnErrorSkip),
+					huh.NewOption("Abort the wizard", "abort"),
+				).
+				Value(&choice),
+		),
+	)
+	if err := pkg.RunHuhForm(ctx, form); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return "", ErrUserAborted
+		}
+		return "", err
+	}
+
+	if answers, ok := pkg.AnswersFrom(ctx); ok {
+		answer, ok := answers[OnErrorAnswerKey]
+		if !ok {
+			return "", errors.Wrapf(actionErr, "missing answer for %s (skip or abort) after %d attempt(s)", OnErrorAnswerKey, attempts)
+		}
+		switch choice := fmt.Sprintf("%v", answer); choice {
+		case OnErrorSkip, "abort":
+			return choice, nil
+		default:
+			return "", errors.Errorf("invalid answer for %s: %s is not one of skip, abort", OnErrorAnswerKey, choice)
+		}
+	}
+	return choice, nil
+}
@@ -0,0 +1,155 @@
+package steps
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// flakyRegistry fails the first `failures` calls of any action callback.
+type flakyRegistry struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyRegistry) ExecuteActionCallback(ctx context.Context, callbackName string, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.Errorf("failure %d", f.calls)
+	}
+	return "ok", nil
+}
+
+func TestErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		policy   *ErrorPolicy
+		calls    int
+		expected map[string]interface{}
+		jump     string
+		fails    bool
+	}{
+		{
+			name:     "success",
+			policy:   &ErrorPolicy{Retries: 2},
+			calls:    1,
+			expected: map[string]interface{}{"out": "ok"},
+		},
+		{
+			name:     "retry until success",
+			failures: 2,
+			policy:   &ErrorPolicy{Retries: 2, Backoff: "1ms"},
+			calls:    3,
+			expected: map[string]interface{}{"out": "ok"},
+		},
+		{
+			name:     "retries exhausted",
+			failures: 5,
+			policy:   &ErrorPolicy{Retries: 1, Backoff: "1ms"},
+			calls:    2,
+			fails:    true,
+		},
+		{
+			name:     "skip with default",
+			failures: 5,
+			policy:   &ErrorPolicy{Retries: 1, Backoff: "1ms", Then: OnErrorSkip, Default: "fallback"},
+			calls:    2,
+			expected: map[string]interface{}{
+				"out":        "fallback",
+				"step_error": map[string]interface{}{"message": "error executing function fn: failure 2", "attempts": 2},
+			},
+		},
+		{
+			name:     "skip with error key",
+			failures: 5,
+			policy:   &ErrorPolicy{Then: OnErrorSkip, ErrorKey: "problem"},
+			calls:    1,
+			expected: map[string]interface{}{
+				"problem": map[string]interface{}{"message": "error executing function fn: failure 1", "attempts": 1},
+			},
+		},
+		{
+			name:     "goto",
+			failures: 5,
+			policy:   &ErrorPolicy{Then: OnErrorGoto, Goto: "recover"},
+			calls:    1,
+			expected: map[string]interface{}{
+				"step_error": map[string]interface{}{"message": "error executing function fn: failure 1", "attempts": 1},
+			},
+			jump: "recover",
+		},
+	}
+	for _, tt := range tests {
+		registry := &flakyRegistry{failures: tt.failures}
+		step := &ActionStep{
+			BaseStep:     BaseStep{StepID: "step", StepType: "action"},
+			ActionType:   "function",
+			FunctionName: "fn",
+			OutputKey:    "out",
+			ShowProgress: boolPtr(false),
+			ShowComplete: boolPtr(false),
+			OnError:      tt.policy,
+		}
+		step.SetCallbackRegistry(registry)
+
+		result, err := step.Execute(context.Background(), map[string]interface{}{})
+		if registry.calls != tt.calls {
+			t.Errorf("%s: expected %d attempts, got %d", tt.name, tt.calls, registry.calls)
+		}
+		var jump *JumpRequest
+		switch {
+		case tt.fails:
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", tt.name, result)
+			}
+			continue
+		case tt.jump != "":
+			if !errors.As(err, &jump) || jump.StepID != tt.jump {
+				t.Errorf("%s: expected a jump to %s, got %v", tt.name, tt.jump, err)
+				continue
+			}
+			result = jump.Result
+		case err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, result)
+		}
+	}
+}
+
+func TestErrorPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *ErrorPolicy
+		problem string
+	}{
+		{"empty", &ErrorPolicy{}, ""},
+		{"skip", &ErrorPolicy{Retries: 3, Backoff: "250ms", Then: OnErrorSkip}, ""},
+		{"negative retries", &ErrorPolicy{Retries: -1}, "must not be negative"},
+		{"bad backoff", &ErrorPolicy{Backoff: "soon"}, "invalid on_error backoff"},
+		{"goto without target", &ErrorPolicy{Then: OnErrorGoto}, "needs a goto step ID"},
+		{"unknown then", &ErrorPolicy{Then: "ignore"}, "unknown on_error then 'ignore'"},
+	}
+	for _, tt := range tests {
+		err := tt.policy.validate("step")
+		if tt.problem == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
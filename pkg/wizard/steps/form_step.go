@@ -3,6 +3,7 @@ package steps
 import (
 	"context"
 
+	"github.com/charmbracelet/huh"
 	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -19,22 +20,37 @@ var _ Step = &FormStep{}
 
 // Execute runs the form defined in the step.
 func (fs *FormStep) Execute(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
-	// TODO(manuel, 2024-08-05) Consider passing state into the form for defaults/pre-population
 	// TODO(manuel, 2024-08-05) Add step title/description rendering
 	log.Debug().Str("stepId", fs.ID()).Msgf("--- Step: %s ---", fs.Title())
 	if fs.Description() != "" {
 		log.Debug().Str("stepId", fs.ID()).Msg(fs.Description())
 	}
 
+	// Render templates in titles, descriptions, defaults and placeholders,
+	// start fields with the values already in the state, then load dynamic
+	// select options (options_from) against it
+	form, err := fs.FormData.Interpolate(state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error rendering form step %s", fs.ID())
+	}
+	form, err = form.Prefill(state).ResolveOptions(ctx, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error resolving options for form step %s", fs.ID())
+	}
+
 	// Run the actual form
 	log.Debug().Str("stepId", fs.ID()).Msg("Running form")
-	formResults, err := fs.FormData.Run(ctx)
+	formResults, err := form.Run(ctx)
 	if err != nil {
 		// Check if the error is ErrUserAborted from the form runner
-		if errors.Is(err, ErrUserAborted) {
+		if errors.Is(err, ErrUserAborted) || errors.Is(err, huh.ErrUserAborted) {
 			log.Debug().Str("stepId", fs.ID()).Msg("Form aborted by user")
 			return nil, ErrUserAborted // Propagate standard wizard abort error
 		}
+		if errors.Is(err, ErrGoBack) {
+			log.Debug().Str("stepId", fs.ID()).Msg("User went back from form")
+			return nil, err
+		}
 		return nil, errors.Wrapf(err, "error running form step %s", fs.ID())
 	}
 	log.Debug().Str("stepId", fs.ID()).Interface("formResults", formResults).Msg("Form completed")
@@ -44,6 +60,34 @@ func (fs *FormStep) Execute(ctx context.Context, state map[string]interface{}) (
 	return formResults, nil
 }
 
+var _ TimeoutDefaulter = &FormStep{}
+
+// DefaultResult returns the answers the form starts with: field defaults, or
+// the values already in the state.
+func (fs *FormStep) DefaultResult(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
+	form, err := fs.FormData.Interpolate(state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error rendering form step %s", fs.ID())
+	}
+	form, err = form.Prefill(state).ResolveOptions(ctx, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error resolving options for form step %s", fs.ID())
+	}
+	return form.DefaultValues()
+}
+
+// Validate checks the title and description templates and the conditions
+// and templates of the form (see pkg.Form.Validate).
+func (fs *FormStep) Validate() error {
+	if err := fs.BaseStep.Validate(); err != nil {
+		return err
+	}
+	if err := fs.FormData.Validate(); err != nil {
+		return errors.Wrapf(err, "invalid form in step %s", fs.ID())
+	}
+	return nil
+}
+
 func (fs *FormStep) GetBaseStep() *BaseStep {
 	return &fs.BaseStep
 }
@@ -70,9 +114,13 @@ func (fs *FormStep) UnmarshalYAML(node *yaml.Node) error {
 	}
 	fs.BaseStep = a.BaseStep
 
-	// Try to decode a full pkg.Form first
-	var full pkg.Form
-	if err := a.Form.Decode(&full); err == nil && (len(full.Groups) > 0) {
+	// A form with groups is a full pkg.Form; its errors (invalid options or
+	// attributes) are reported instead of falling back to the simple schema.
+	if hasKey(&a.Form, "groups") {
+		var full pkg.Form
+		if err := a.Form.Decode(&full); err != nil {
+			return errors.Wrap(err, "could not decode form step")
+		}
 		fs.FormData = full
 		return nil
 	}
@@ -91,6 +139,8 @@ func (fs *FormStep) UnmarshalYAML(node *yaml.Node) error {
 			fieldType = "input"
 		case "confirm", "bool":
 			fieldType = "confirm"
+		case "number", "integer", "date", "datetime":
+			fieldType = f.Type
 		}
 		pf := &pkg.Field{
 			Type:     fieldType,
@@ -103,3 +153,16 @@ func (fs *FormStep) UnmarshalYAML(node *yaml.Node) error {
 	fs.FormData = pkg.Form{Groups: []*pkg.Group{grp}}
 	return nil
 }
+
+// hasKey reports whether node is a mapping with the given key.
+func hasKey(node *yaml.Node, key string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package steps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultHTTPTimeout bounds http actions that don't set a request_timeout.
+const defaultHTTPTimeout = 30 * time.Second
+
+// maxErrorBodyLength is how much of an unexpected response body is quoted in
+// the error.
+const maxErrorBodyLength = 200
+
+// executeHTTP sends the step's request. The method, url, header values and
+// body are templates rendered against the state. A map or list body is sent as
+// JSON.
+//
+// The response is stored under output_key as a map with status, headers and
+// body (decoded if the response is JSON), also when the status is not
+// expected. Each response_mapping entry stores the value at a JSON path of the
+// decoded body under its state key.
+func (as *ActionStep) executeHTTP(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
+	if as.URL == "" {
+		return nil, errors.New("url not specified for http-type action")
+	}
+
+	method, err := pkg.Interpolate(as.Method, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not render method of step %s", as.ID())
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	url, err := pkg.Interpolate(as.URL, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not render url of step %s", as.ID())
+	}
+	body, contentType, err := as.renderBody(state)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultHTTPTimeout
+	if as.RequestTimeout != "" {
+		timeout, err = time.ParseDuration(as.RequestTimeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid request_timeout in step %s", as.ID())
+		}
+	}
+	// Only the request is bounded by request_timeout, not the notes shown
+	// around it
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, strings.ToUpper(method), url, body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create request of step %s", as.ID())
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range as.Headers {
+		rendered, err := pkg.Interpolate(v, state)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not render header %s of step %s", k, as.ID())
+		}
+		req.Header.Set(k, rendered)
+	}
+
+	title, _, err := as.renderHeader(state)
+	if err != nil {
+		return nil, err
+	}
+	showProgress := boolValue(as.ShowProgress, true)
+	stopProgress := func() {}
+	if showProgress {
+		stopProgress = as.startProgress(ctx, title, fmt.Sprintf("%s %s", req.Method, url))
+	}
+	defer stopProgress()
+
+	log.Debug().Str("stepId", as.ID()).Str("method", req.Method).Str("url", url).Msg("Sending http request")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "request of step %s failed", as.ID())
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read response of step %s", as.ID())
+	}
+	stopProgress()
+
+	var decoded interface{} = string(data)
+	isJSON := false
+	if len(bytes.TrimSpace(data)) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			decoded = v
+			isJSON = true
+		}
+	}
+
+	headers := map[string]interface{}{}
+	for k := range resp.Header {
+		headers[k] = resp.Header.Get(k)
+	}
+
+	stepResult := map[string]interface{}{}
+	if as.OutputKey != "" {
+		stepResult[as.OutputKey] = map[string]interface{}{
+			"status":  resp.StatusCode,
+			"headers": headers,
+			"body":    decoded,
+		}
+	}
+
+	if !as.statusExpected(resp.StatusCode) {
+		return stepResult, errors.Errorf("step %s: unexpected status %d from %s %s: %s",
+			as.ID(), resp.StatusCode, req.Method, url, truncate(strings.TrimSpace(string(data)), maxErrorBodyLength))
+	}
+
+	if len(as.ResponseMapping) > 0 && !isJSON {
+		return stepResult, errors.Errorf("step %s: response_mapping needs a JSON response, got %q", as.ID(), resp.Header.Get("Content-Type"))
+	}
+	for key, path := range as.ResponseMapping {
+		v, err := LookupJSONPath(decoded, path)
+		if err != nil {
+			return stepResult, errors.Wrapf(err, "step %s: could not map %s", as.ID(), key)
+		}
+		stepResult[key] = v
+		log.Debug().Str("stepId", as.ID()).Str("key", key).Str("path", path).Interface("value", v).Msg("Mapped response value")
+	}
+
+	if boolValue(as.ShowComplete, true) {
+		if err := as.showCompletionNote(ctx, fmt.Sprintf("%s %s returned %s.", req.Method, url, resp.Status)); err != nil {
+			return nil, err
+		}
+	}
+
+	return stepResult, nil
+}
+
+// renderBody returns the request body and, for JSON bodies, its content type.
+func (as *ActionStep) renderBody(state map[string]interface{}) (io.Reader, string, error) {
+	if as.Body == nil {
+		return nil, "", nil
+	}
+	rendered, err := pkg.InterpolateValue(as.Body, state)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "could not render body of step %s", as.ID())
+	}
+	if s, ok := rendered.(string); ok {
+		return strings.NewReader(s), "", nil
+	}
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "could not encode body of step %s as JSON", as.ID())
+	}
+	return bytes.NewReader(data), "application/json", nil
+}
+
+// statusExpected reports whether status is one of expect_status, or a 2xx
+// status if expect_status is empty.
+func (as *ActionStep) statusExpected(status int) bool {
+	if len(as.ExpectStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(as.ExpectStatus, status)
+}
+
+// validateHTTP checks the templates, timeout and response mapping paths of
+// an http action.
+func (as *ActionStep) validateHTTP() error {
+	if as.URL == "" {
+		return errors.Errorf("step %s: url is required for action_type http", as.ID())
+	}
+	for name, s := range map[string]string{"method": as.Method, "url": as.URL} {
+		if _, err := pkg.ParseTemplate(as.ID(), s); err != nil {
+			return errors.Wrapf(err, "invalid %s template in step %s", name, as.ID())
+		}
+	}
+	if as.RequestTimeout != "" {
+		if _, err := time.ParseDuration(as.RequestTimeout); err != nil {
+			return errors.Wrapf(err, "invalid request_timeout in step %s", as.ID())
+		}
+	}
+	for key, path := range as.ResponseMapping {
+		if _, err := parseJSONPath(path); err != nil {
+			return errors.Wrapf(err, "invalid response_mapping path for %s in step %s", key, as.ID())
+		}
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// jsonPathSegment is a map key, a list index, or `*` for every list item.
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// LookupJSONPath returns the value at path in a decoded JSON document. Paths
+// use a subset of JSONPath: an optional leading `$`, `.key` or `["key"]` for
+// object members, `[0]` for list items and `[*]` for every item of a list, in
+// which case the rest of the path is applied to each item and a list is
+// returned. `$` alone returns the whole document.
+func LookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return lookupSegments(doc, segments, "$")
+}
+
+func lookupSegments(v interface{}, segments []jsonPathSegment, at string) (interface{}, error) {
+	if len(segments) == 0 {
+		return v, nil
+	}
+	seg := segments[0]
+	switch {
+	case seg.wildcard:
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s is not a list", at)
+		}
+		ret := make([]interface{}, 0, len(list))
+		for i, item := range list {
+			itemValue, err := lookupSegments(item, segments[1:], fmt.Sprintf("%s[%d]", at, i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, itemValue)
+		}
+		return ret, nil
+	case seg.isIndex:
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s is not a list", at)
+		}
+		if seg.index >= len(list) {
+			return nil, errors.Errorf("%s has %d items, no index %d", at, len(list), seg.index)
+		}
+		return lookupSegments(list[seg.index], segments[1:], fmt.Sprintf("%s[%d]", at, seg.index))
+	default:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s is not an object", at)
+		}
+		next, ok := m[seg.key]
+		if !ok {
+			return nil, errors.Errorf("%s has no key %q", at, seg.key)
+		}
+		return lookupSegments(next, segments[1:], at+"."+seg.key)
+	}
+}
+
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	rest := strings.TrimSpace(path)
+	if rest == "" {
+		return nil, errors.New("empty JSON path")
+	}
+	rest = strings.TrimPrefix(rest, "$")
+
+	var segments []jsonPathSegment
+	first := true
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			rest = rest[1:]
+			n := strings.IndexAny(rest, ".[")
+			if n == -1 {
+				n = len(rest)
+			}
+			if n == 0 {
+				return nil, errors.Errorf("empty key in JSON path %q", path)
+			}
+			segments = append(segments, jsonPathSegment{key: rest[:n]})
+			rest = rest[n:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, errors.Errorf("unclosed [ in JSON path %q", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil || idx < 0 {
+					return nil, errors.Errorf("invalid index [%s] in JSON path %q", inner, path)
+				}
+				segments = append(segments, jsonPathSegment{index: idx, isIndex: true})
+			}
+		case first:
+			// Paths may leave out the leading `$.`
+			rest = "." + rest
+			continue
+		default:
+			return nil, errors.Errorf("unexpected %q in JSON path %q", rest, path)
+		}
+		first = false
+	}
+	return segments, nil
+}
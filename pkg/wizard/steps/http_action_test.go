@@ -0,0 +1,239 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHTTPStep returns an http action that shows no notes, so it runs without
+// a terminal.
+func newHTTPStep(url string) *ActionStep {
+	quiet := false
+	return &ActionStep{
+		BaseStep:     BaseStep{StepID: "fetch", StepType: "action"},
+		ActionType:   "http",
+		URL:          url,
+		ShowProgress: &quiet,
+		ShowComplete: &quiet,
+	}
+}
+
+func TestExecuteHTTPStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"message": "Not Found"}`)
+	}))
+	defer server.Close()
+
+	step := newHTTPStep(server.URL + "/missing")
+	step.OutputKey = "response"
+	result, err := step.Execute(context.Background(), map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "unexpected status 404") {
+		t.Fatalf("expected an unexpected status error, got %v", err)
+	}
+	response, ok := result["response"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the response to be stored under output_key, got %#v", result)
+	}
+	if response["status"] != http.StatusNotFound {
+		t.Errorf("expected status 404, got %v", response["status"])
+	}
+	if body, _ := response["body"].(map[string]interface{}); body["message"] != "Not Found" {
+		t.Errorf("expected the decoded body, got %#v", response["body"])
+	}
+
+	step.ExpectStatus = []int{http.StatusNotFound}
+	if _, err := step.Execute(context.Background(), map[string]interface{}{}); err != nil {
+		t.Errorf("expected 404 to be accepted with expect_status, got %v", err)
+	}
+}
+
+func TestExecuteHTTPResponseMapping(t *testing.T) {
+	var gotMethod, gotAuth string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `[
+			{"name": "uhoh", "owner": {"login": "go-go-golems"}, "topics": ["tui", "forms"]},
+			{"name": "glazed", "owner": {"login": "go-go-golems"}, "topics": ["cli"]}
+		]`)
+	}))
+	defer server.Close()
+
+	step := newHTTPStep(server.URL + "/users/{{ .user }}/repos")
+	step.Method = "post"
+	step.Headers = map[string]string{"Authorization": "Bearer {{ .token }}"}
+	step.Body = map[string]interface{}{"owner": "{{ .user }}"}
+	step.ResponseMapping = map[string]string{
+		"names":        "$[*].name",
+		"first_owner":  "$[0].owner.login",
+		"first_topics": "[0].topics",
+		"all_topics":   "$[*].topics[0]",
+	}
+
+	result, err := step.Execute(context.Background(), map[string]interface{}{"user": "go-go-golems", "token": "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotMethod != http.MethodPost {
+		t.Errorf("expected a POST request, got %s", gotMethod)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("expected the rendered header, got %q", gotAuth)
+	}
+	if gotBody["owner"] != "go-go-golems" {
+		t.Errorf("expected the rendered JSON body, got %#v", gotBody)
+	}
+
+	expected := map[string]interface{}{
+		"names":        []interface{}{"uhoh", "glazed"},
+		"first_owner":  "go-go-golems",
+		"first_topics": []interface{}{"tui", "forms"},
+		"all_topics":   []interface{}{"tui", "cli"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %#v, got %#v", expected, result)
+	}
+}
+
+func TestExecuteHTTPEscapedURL(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotQuery = r.URL.Query().Get("q")
+	}))
+	defer server.Close()
+
+	step := newHTTPStep(server.URL + "/repos/{{ .repo | pathescape }}/issues?q={{ .query | urlquery }}")
+	state := map[string]interface{}{"repo": "../admin?x=1", "query": "a&b=c"}
+	if _, err := step.Execute(context.Background(), state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "/repos/..%2Fadmin%3Fx=1/issues" {
+		t.Errorf("expected the value to stay in one path segment, got %s", gotPath)
+	}
+	if gotQuery != "a&b=c" {
+		t.Errorf("expected the query value to be escaped, got %q", gotQuery)
+	}
+}
+
+func TestExecuteHTTPMappingErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			_, _ = io.WriteString(w, "plain text")
+			return
+		}
+		_, _ = io.WriteString(w, `{"items": []}`)
+	}))
+	defer server.Close()
+
+	step := newHTTPStep(server.URL + "/json")
+	step.ResponseMapping = map[string]string{"first": "$.items[0].id"}
+	if _, err := step.Execute(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "no index 0") {
+		t.Errorf("expected a missing index error, got %v", err)
+	}
+
+	step = newHTTPStep(server.URL + "/text")
+	step.ResponseMapping = map[string]string{"first": "$.id"}
+	if _, err := step.Execute(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "needs a JSON response") {
+		t.Errorf("expected a JSON response error, got %v", err)
+	}
+}
+
+func TestExecuteHTTPRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	step := newHTTPStep(server.URL)
+	step.RequestTimeout = "50ms"
+	start := time.Now()
+	if _, err := step.Execute(context.Background(), nil); err == nil {
+		t.Fatal("expected the request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request_timeout was not applied, the request took %s", elapsed)
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"repos": [
+			{"name": "a", "branches": [{"id": 1}, {"id": 2}]},
+			{"name": "b", "branches": []}
+		],
+		"odd key": {"x": true}
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"$", doc},
+		{"$.repos[1].name", "b"},
+		{"repos[0].name", "a"},
+		{`$["odd key"].x`, true},
+		{`$['odd key']['x']`, true},
+		{"$.repos[*].name", []interface{}{"a", "b"}},
+		{"$.repos[*].branches[*].id", []interface{}{[]interface{}{1.0, 2.0}, []interface{}{}}},
+		{"$.repos[0].branches[*]", []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}}},
+	}
+	for _, tt := range tests {
+		got, err := LookupJSONPath(doc, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.path, tt.expected, got)
+		}
+	}
+
+	for _, path := range []string{"$.missing", "$.repos.name", "$.repos[5]", "$['odd key'][0]", "$.repos[*].branches[0].id"} {
+		if _, err := LookupJSONPath(doc, path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	segments, err := parseJSONPath(`$.a[2]["b.c"][*]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []jsonPathSegment{
+		{key: "a"},
+		{index: 2, isIndex: true},
+		{key: "b.c"},
+		{wildcard: true},
+	}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Errorf("expected %#v, got %#v", expected, segments)
+	}
+
+	for _, path := range []string{"", "$.", "$.a[", "$.a[-1]", "$.a[x]", "$..a", "$.a[0]x"} {
+		if _, err := parseJSONPath(path); err == nil {
+			t.Errorf("%q: expected an error", path)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/huh"
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -20,25 +21,37 @@ var _ Step = &InfoStep{}
 func (is *InfoStep) Execute(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
 	log.Debug().Str("stepId", is.ID()).Msgf("--- Step: %s ---", is.Title())
 
+	title, description, err := is.renderHeader(state)
+	if err != nil {
+		return nil, err
+	}
+	content, err := pkg.Interpolate(is.Content, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not render content of step %s", is.ID())
+	}
+
 	// Build the content, potentially combining the description and content
-	displayContent := is.Content
+	displayContent := content
 
-	if is.Description() != "" {
+	if description != "" {
 		// Add the description as a header if it exists
-		displayContent = fmt.Sprintf("%s\n\n%s", is.Description(), is.Content)
+		displayContent = fmt.Sprintf("%s\n\n%s", description, content)
 	}
 
 	// Create a note component to display the information
 	note := huh.NewNote().
-		Title(is.Title()).
+		Title(title).
 		Description(displayContent)
 
 	// Show the note
-	err := note.Run()
+	err = pkg.RunHuhForm(ctx, huh.NewForm(huh.NewGroup(note)))
 	if err != nil {
 		if errors.Is(err, huh.ErrUserAborted) {
 			return nil, ErrUserAborted
 		}
+		if errors.Is(err, ErrGoBack) {
+			return nil, err
+		}
 		return nil, errors.Wrap(err, "error displaying info step")
 	}
 
@@ -46,6 +59,17 @@ func (is *InfoStep) Execute(ctx context.Context, state map[string]interface{}) (
 	return map[string]interface{}{}, nil
 }
 
+// Validate checks the title, description and content templates.
+func (is *InfoStep) Validate() error {
+	if err := is.BaseStep.Validate(); err != nil {
+		return err
+	}
+	if _, err := pkg.ParseTemplate(is.ID(), is.Content); err != nil {
+		return errors.Wrapf(err, "invalid content template in step %s", is.ID())
+	}
+	return nil
+}
+
 func (is *InfoStep) GetBaseStep() *BaseStep {
 	return &is.BaseStep
 }
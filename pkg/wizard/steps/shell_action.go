@@ -0,0 +1,87 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ShellActionOptions configures a shell action, with templates already
+// rendered.
+type ShellActionOptions struct {
+	Title    string
+	WorkDir  string
+	Env      []string // KEY=value pairs added to the environment
+	KeepOpen bool
+}
+
+// ShellActionRunner is implemented by callback registries that can run the
+// command of an `action_type: shell` step. The wizard runner implements it
+// with the shellcmd viewer. The returned map is stored under the step's
+// output_key, also when the command fails.
+type ShellActionRunner interface {
+	RunShellAction(ctx context.Context, command string, opts ShellActionOptions) (map[string]interface{}, error)
+}
+
+// executeShell runs the step's command. The command, workdir and env values
+// are templates rendered against the state. The state is also passed to the
+// command as UHOH_* environment variables (see pkg.StateEnv), which the step's
+// env overrides.
+func (as *ActionStep) executeShell(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
+	if as.Command == "" {
+		return nil, errors.New("command not specified for shell-type action")
+	}
+	runner, ok := as.registry.(ShellActionRunner)
+	if !ok {
+		return nil, errors.Errorf("shell actions are not supported by this wizard runner (step %s)", as.ID())
+	}
+
+	title, _, err := as.renderHeader(state)
+	if err != nil {
+		return nil, err
+	}
+	command, err := pkg.Interpolate(as.Command, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not render command of step %s", as.ID())
+	}
+	workDir, err := pkg.Interpolate(as.WorkDir, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not render workdir of step %s", as.ID())
+	}
+	env := make([]string, 0, len(as.Env))
+	for k, v := range as.Env {
+		rendered, err := pkg.Interpolate(v, state)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not render env %s of step %s", k, as.ID())
+		}
+		env = append(env, fmt.Sprintf("%s=%s", k, rendered))
+	}
+	sort.Strings(env)
+	// Later entries win, so the step's env overrides the state
+	env = append(pkg.StateEnv(state), env...)
+
+	if title == "" {
+		title = as.ID()
+	}
+
+	log.Debug().Str("stepId", as.ID()).Str("command", command).Str("workdir", workDir).Msg("Running shell action")
+	result, runErr := runner.RunShellAction(ctx, command, ShellActionOptions{
+		Title:    title,
+		WorkDir:  workDir,
+		Env:      env,
+		KeepOpen: as.KeepOpen,
+	})
+
+	stepResult := map[string]interface{}{}
+	if as.OutputKey != "" && result != nil {
+		stepResult[as.OutputKey] = result
+	}
+	if runErr != nil {
+		return stepResult, errors.Wrapf(runErr, "shell command of step %s failed", as.ID())
+	}
+	return stepResult, nil
+}
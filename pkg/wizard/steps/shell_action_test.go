@@ -0,0 +1,70 @@
+package steps
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// fakeShellRunner records the shell commands it is asked to run.
+type fakeShellRunner struct {
+	command string
+	opts    ShellActionOptions
+	result  map[string]interface{}
+	err     error
+}
+
+func (f *fakeShellRunner) ExecuteActionCallback(ctx context.Context, callbackName string, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+	return nil, errors.Errorf("unexpected callback %s", callbackName)
+}
+
+func (f *fakeShellRunner) RunShellAction(ctx context.Context, command string, opts ShellActionOptions) (map[string]interface{}, error) {
+	f.command, f.opts = command, opts
+	return f.result, f.err
+}
+
+func TestExecuteShell(t *testing.T) {
+	runner := &fakeShellRunner{result: map[string]interface{}{"exit_code": 0}}
+	step := &ActionStep{
+		BaseStep:   BaseStep{StepID: "build", StepType: "action"},
+		ActionType: "shell",
+		Command:    "make {{ .target | shellquote }}",
+		WorkDir:    "{{ .dir }}",
+		Env:        map[string]string{"UHOH_TARGET": "override", "MODE": "{{ .mode }}"},
+		OutputKey:  "build_result",
+	}
+	step.SetCallbackRegistry(runner)
+
+	result, err := step.Execute(context.Background(), map[string]interface{}{
+		"target": "it's",
+		"dir":    "/tmp",
+		"mode":   "fast",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `make 'it'\''s'`; runner.command != expected {
+		t.Errorf("expected the command %s, got %s", expected, runner.command)
+	}
+	if runner.opts.Title != "build" || runner.opts.WorkDir != "/tmp" {
+		t.Errorf("expected the title build and workdir /tmp, got %+v", runner.opts)
+	}
+	expectedEnv := []string{"UHOH_DIR=/tmp", "UHOH_MODE=fast", "UHOH_TARGET=it's", "MODE=fast", "UHOH_TARGET=override"}
+	if !reflect.DeepEqual(runner.opts.Env, expectedEnv) {
+		t.Errorf("expected the environment %q, got %q", expectedEnv, runner.opts.Env)
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"build_result": runner.result}) {
+		t.Errorf("expected the result under build_result, got %v", result)
+	}
+
+	runner.err = errors.New("exit status 2")
+	result, err = step.Execute(context.Background(), map[string]interface{}{})
+	if err == nil {
+		t.Error("expected the failure of the command")
+	}
+	if result["build_result"] == nil {
+		t.Errorf("expected the result to be kept when the command fails, got %v", result)
+	}
+}
@@ -2,16 +2,25 @@ package steps
 
 import (
 	"context"
+	"time"
 
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
+// EndStepID is the next_step target that finishes the wizard. It cannot be
+// used as a step ID.
+const EndStepID = "end"
+
 // Standard errors
 var (
 	ErrUserAborted        = errors.New("user aborted")
 	ErrStepNotImplemented = errors.New("step not implemented")
+	// ErrGoBack is returned by a step to return to the previously executed
+	// step. Forms return it when the user presses pkg.BackKey.
+	ErrGoBack = pkg.ErrGoBack
 )
 
 // Step represents a single step in the wizard.
@@ -30,6 +39,13 @@ type Step interface {
 	NavigationCallback() string
 }
 
+// Validator is implemented by steps that can check their definition (for
+// example the syntax of their templates) when the wizard is loaded, so mistakes
+// are reported before the first step runs.
+type Validator interface {
+	Validate() error
+}
+
 // BaseStep contains common fields for all step types.
 type BaseStep struct {
 	StepID                 string `yaml:"id"`
@@ -42,6 +58,27 @@ type BaseStep struct {
 	StepAfterCallback      string `yaml:"after,omitempty"`
 	StepValidationCallback string `yaml:"validation,omitempty"`
 	StepNavigationCallback string `yaml:"navigation,omitempty"`
+	// StepTimeout bounds how long the step and each of its callbacks may take,
+	// e.g. "30s". StepOnTimeout is what happens when it expires (see the
+	// OnTimeout constants).
+	StepTimeout   string `yaml:"timeout,omitempty"`
+	StepOnTimeout string `yaml:"on_timeout,omitempty"`
+}
+
+// What the runner does when a step timeout expires.
+const (
+	// OnTimeoutFail aborts the wizard with a timeout error (the default).
+	OnTimeoutFail = "fail"
+	// OnTimeoutSkip continues with the next step, without a result.
+	OnTimeoutSkip = "skip"
+	// OnTimeoutDefault continues with the step's default answers (form steps).
+	OnTimeoutDefault = "default"
+)
+
+// TimeoutDefaulter is implemented by steps that can answer without the user,
+// for on_timeout: default.
+type TimeoutDefaulter interface {
+	DefaultResult(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error)
 }
 
 func (bs *BaseStep) ID() string {
@@ -81,6 +118,60 @@ func (bs *BaseStep) NavigationCallback() string {
 	return bs.StepNavigationCallback
 }
 
+// Validate checks that the step title and description are valid templates
+// and that the timeout settings are valid.
+func (bs *BaseStep) Validate() error {
+	if _, err := pkg.ParseTemplate("title", bs.StepTitle); err != nil {
+		return errors.Wrapf(err, "invalid title template in step %s", bs.StepID)
+	}
+	if _, err := pkg.ParseTemplate("description", bs.StepDescription); err != nil {
+		return errors.Wrapf(err, "invalid description template in step %s", bs.StepID)
+	}
+	if _, err := bs.Timeout(); err != nil {
+		return err
+	}
+	switch bs.StepOnTimeout {
+	case "", OnTimeoutFail, OnTimeoutSkip:
+	case OnTimeoutDefault:
+		if bs.StepType != "form" {
+			return errors.Errorf("step %s: on_timeout default is only supported by form steps", bs.StepID)
+		}
+	default:
+		return errors.Errorf("step %s: unknown on_timeout '%s' (expected %s, %s or %s)",
+			bs.StepID, bs.StepOnTimeout, OnTimeoutFail, OnTimeoutSkip, OnTimeoutDefault)
+	}
+	return nil
+}
+
+// Timeout returns the parsed step timeout, or 0 if the step has none.
+func (bs *BaseStep) Timeout() (time.Duration, error) {
+	if bs.StepTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(bs.StepTimeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid timeout in step %s", bs.StepID)
+	}
+	if d <= 0 {
+		return 0, errors.Errorf("step %s: timeout must be positive", bs.StepID)
+	}
+	return d, nil
+}
+
+// renderHeader interpolates the step title and description against the wizard
+// state (see pkg.Interpolate).
+func (bs *BaseStep) renderHeader(state map[string]interface{}) (string, string, error) {
+	title, err := pkg.Interpolate(bs.StepTitle, state)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "could not render title of step %s", bs.StepID)
+	}
+	description, err := pkg.Interpolate(bs.StepDescription, state)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "could not render description of step %s", bs.StepID)
+	}
+	return title, description, nil
+}
+
 // Placeholder Execute for BaseStep - concrete types should override this.
 func (bs *BaseStep) Execute(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
 	log.Error().Str("stepId", bs.ID()).Str("stepType", bs.Type()).Msg("Execute not implemented for step")
@@ -160,6 +251,25 @@ func UnmarshalStepYAML(node *yaml.Node) (Step, error) {
 	return step, nil
 }
 
+// NewStep returns an empty step of the given step type, or nil if the type
+// is unknown.
+func NewStep(stepType string) Step {
+	switch stepType {
+	case "form":
+		return &FormStep{}
+	case "decision":
+		return &DecisionStep{}
+	case "action":
+		return &ActionStep{}
+	case "info":
+		return &InfoStep{}
+	case "summary":
+		return &SummaryStep{}
+	default:
+		return nil
+	}
+}
+
 // Custom Unmarshaller for Wizard.Steps
 // We need this because []Step is a slice of interfaces.
 func (w *WizardSteps) UnmarshalYAML(node *yaml.Node) error {
@@ -3,9 +3,13 @@ package steps
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"text/template"
 
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/huh"
+	"github.com/go-go-golems/uhoh/pkg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -23,28 +27,58 @@ type SummaryStep struct {
 	BaseStep `yaml:",inline"`
 	Sections []SummarySection `yaml:"sections"`
 	Editable bool             `yaml:"editable,omitempty"`
-	Template string           `yaml:"template,omitempty"` // Optional Go template to format state
+	Template string           `yaml:"template,omitempty"` // Optional Go template (markdown) rendered against the state
 }
 
 var _ Step = &SummaryStep{}
 
+// Validate checks the summary template when the wizard is loaded, so a syntax
+// error is reported with its line number before the first step runs.
+func (ss *SummaryStep) Validate() error {
+	if err := ss.BaseStep.Validate(); err != nil {
+		return err
+	}
+	if _, err := ss.parseTemplate(); err != nil {
+		return errors.Wrapf(err, "invalid template in summary step %s", ss.ID())
+	}
+	return nil
+}
+
+func (ss *SummaryStep) parseTemplate() (*template.Template, error) {
+	return pkg.ParseTemplate(ss.ID(), ss.Template)
+}
+
 func (ss *SummaryStep) Execute(ctx context.Context, state map[string]interface{}) (map[string]interface{}, error) {
 	log.Debug().Str("stepId", ss.ID()).Msgf("--- Step: %s ---", ss.Title())
 
-	// If using template mode, we'll handle that separately
-	if ss.Template != "" {
-		// TODO: In the future, implement template-based rendering
-		log.Warn().Str("stepId", ss.ID()).Msg("Template-based summary not yet implemented")
+	title, description, err := ss.renderHeader(state)
+	if err != nil {
+		return nil, err
 	}
 
-	// Build a formatted summary text from the sections and state
+	// Build a markdown summary, either from the template or from the sections
 	var sb strings.Builder
 
-	// If no sections defined, show all state
-	if len(ss.Sections) == 0 {
+	if ss.Template != "" {
+		tmpl, err := ss.parseTemplate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid template in summary step %s", ss.ID())
+		}
+		out, err := pkg.ExecuteTemplate(tmpl, state)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not render template of summary step %s", ss.ID())
+		}
+		sb.WriteString(out)
+	} else if len(ss.Sections) == 0 {
+		// If no sections defined, show all state
 		sb.WriteString("## Current State\n\n")
-		for k, v := range state {
-			sb.WriteString(fmt.Sprintf("- **%s**: %v\n", k, v))
+		keys := make([]string, 0, len(state))
+		for k := range state {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("- **%s**: %v\n", k, state[k]))
 		}
 	} else {
 		// Process each defined section
@@ -68,38 +102,114 @@ func (ss *SummaryStep) Execute(ctx context.Context, state map[string]interface{}
 		}
 	}
 
+	// Headless runs keep the markdown as is, so recorded views do not depend
+	// on the terminal
+	summary := sb.String()
+	if !pkg.IsHeadless(ctx) {
+		summary = renderMarkdown(summary)
+	}
+
 	// Create a note to display the summary
 	note := huh.NewNote().
-		Title(ss.Title()).
-		Description(sb.String())
+		Title(title).
+		Description(summary)
 
-	if ss.Description() != "" {
+	if description != "" {
 		// Include the description as part of the note's description
 		note = huh.NewNote().
-			Title(ss.Title()).
-			Description(ss.Description() + "\n\n" + sb.String())
+			Title(title).
+			Description(description + "\n\n" + summary)
+	}
+
+	fields := []huh.Field{note}
+
+	// An editable summary asks which answer to change. The runner then re-runs
+	// the step that set it and comes back here.
+	var editKey string
+	if ss.Editable {
+		if options := ss.editOptions(ctx, state); len(options) > 0 {
+			options = append([]huh.Option[string]{huh.NewOption("No, continue", "")}, options...)
+			fields = append(fields, huh.NewSelect[string]().
+				Title("Anything to change?").
+				Options(options...).
+				Value(&editKey))
+		}
 	}
 
 	// Show the note
-	err := note.Run()
+	err = pkg.RunHuhForm(ctx, huh.NewForm(huh.NewGroup(fields...)))
 	if err != nil {
 		if errors.Is(err, huh.ErrUserAborted) {
 			return nil, ErrUserAborted
 		}
+		if errors.Is(err, ErrGoBack) {
+			return nil, err
+		}
 		return nil, errors.Wrap(err, "error displaying summary step")
 	}
 
-	// If editable is true, we would need to allow navigation back to edit fields
-	// This would require more complex navigation logic in the wizard runner
-	if ss.Editable {
-		log.Warn().Str("stepId", ss.ID()).Msg("Editable summary not fully implemented yet")
-		// TODO: Implement field editing functionality
+	if editKey != "" {
+		log.Debug().Str("stepId", ss.ID()).Str("key", editKey).Msg("User asked to edit a field")
+		return nil, &EditRequest{Key: editKey}
 	}
 
-	// Summary steps don't modify state unless editable with changes
+	// Summary steps don't modify state
 	return map[string]interface{}{}, nil
 }
 
+// editOptions lists the displayed fields that can be edited, that is the ones
+// set by an earlier step, labelled with the step that set them.
+func (ss *SummaryStep) editOptions(ctx context.Context, state map[string]interface{}) []huh.Option[string] {
+	sources := FieldSources(ctx)
+
+	var keys []string
+	if ss.Template == "" && len(ss.Sections) > 0 {
+		for _, section := range ss.Sections {
+			keys = append(keys, section.Fields...)
+		}
+	} else {
+		for k := range state {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+
+	options := []huh.Option[string]{}
+	seen := map[string]bool{}
+	for _, k := range keys {
+		source, ok := sources[k]
+		if !ok || seen[k] {
+			continue
+		}
+		seen[k] = true
+		stepName := source.StepTitle
+		if stepName == "" {
+			stepName = source.StepID
+		}
+		options = append(options, huh.NewOption(fmt.Sprintf("%s (%s)", k, stepName), k))
+	}
+	return options
+}
+
 func (ss *SummaryStep) GetBaseStep() *BaseStep {
 	return &ss.BaseStep
 }
+
+// renderMarkdown renders markdown for the terminal with glamour. If rendering
+// fails, the markdown is shown as is.
+func renderMarkdown(markdown string) string {
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(80),
+	)
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not create markdown renderer")
+		return markdown
+	}
+	out, err := renderer.Render(markdown)
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not render markdown")
+		return markdown
+	}
+	return strings.Trim(out, "\n")
+}
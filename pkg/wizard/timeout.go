@@ -0,0 +1,71 @@
+package wizard
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+	"github.com/pkg/errors"
+)
+
+// timeout returns the parsed wizard timeout, or 0 if the wizard has none.
+func (w *Wizard) timeout() (time.Duration, error) {
+	if w.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(w.Timeout)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid wizard timeout")
+	}
+	if d <= 0 {
+		return 0, errors.New("wizard timeout must be positive")
+	}
+	return d, nil
+}
+
+// checkWizardTimeout returns an error if the wizard timeout expired.
+func (w *Wizard) checkWizardTimeout(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.Wrapf(ctx.Err(), "wizard '%s' timed out after %s", w.Name, w.Timeout)
+	}
+	return nil
+}
+
+// withStepTimeout derives the context a step, or one of its callbacks, runs
+// with from the wizard context, bounded by the step timeout if it has one.
+// Timeouts are checked in LoadWizard.
+func withStepTimeout(ctx context.Context, step steps.Step) (context.Context, context.CancelFunc) {
+	d, err := step.GetBaseStep().Timeout()
+	if err != nil || d == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// stepTimedOut reports whether stepCtx expired because of the step timeout,
+// rather than because the wizard context ended.
+func stepTimedOut(ctx context.Context, stepCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded)
+}
+
+// handleStepTimeout returns the result of a step whose timeout expired,
+// according to its on_timeout setting.
+func handleStepTimeout(ctx context.Context, step steps.Step, state map[string]interface{}) (map[string]interface{}, error) {
+	bs := step.GetBaseStep()
+	switch bs.StepOnTimeout {
+	case steps.OnTimeoutSkip:
+		return map[string]interface{}{}, nil
+	case steps.OnTimeoutDefault:
+		defaulter, ok := step.(steps.TimeoutDefaulter)
+		if !ok {
+			return nil, errors.Errorf("step %s timed out and has no default answers", step.ID())
+		}
+		result, err := defaulter.DefaultResult(ctx, state)
+		if err != nil {
+			return nil, errors.Wrapf(err, "step %s timed out and its default answers could not be computed", step.ID())
+		}
+		return result, nil
+	default:
+		return nil, errors.Wrapf(context.DeadlineExceeded, "step %s timed out after %s", step.ID(), bs.StepTimeout)
+	}
+}
@@ -0,0 +1,96 @@
+package wizard
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// waitForCancel is an action callback that blocks until its context ends.
+func waitForCancel(ctx context.Context, state map[string]interface{}, args map[string]interface{}) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRunTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		wizard   string
+		expected []string
+		problem  string
+	}{
+		{
+			name: "step fails",
+			wizard: `
+steps:
+  - {id: slow, type: action, action_type: function, function_name: wait, show_progress: false, show_completion: false, timeout: 10ms}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false}
+`,
+			expected: []string{},
+			problem:  "step slow timed out after 10ms",
+		},
+		{
+			name: "step is skipped",
+			wizard: `
+steps:
+  - {id: slow, type: action, action_type: function, function_name: wait, show_progress: false, show_completion: false, timeout: 10ms, on_timeout: skip}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false}
+`,
+			expected: []string{"b"},
+		},
+		{
+			name: "wizard times out",
+			wizard: `
+name: Slow
+timeout: 20ms
+steps:
+  - {id: a, type: action, action_type: function, function_name: visit, arguments: {id: a}, show_progress: false, show_completion: false}
+  - {id: slow, type: action, action_type: function, function_name: wait, show_progress: false, show_completion: false, timeout: 1h, on_timeout: skip}
+  - {id: b, type: action, action_type: function, function_name: visit, arguments: {id: b}, show_progress: false, show_completion: false}
+`,
+			expected: []string{"a"},
+			problem:  "wizard 'Slow' timed out after 20ms",
+		},
+	}
+	for _, tt := range tests {
+		visited, _, err := visitWizard(t, tt.wizard, WithActionCallback("wait", waitForCancel))
+		if tt.problem != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(visited, tt.expected) {
+			t.Errorf("%s: expected the steps %v to run, got %v", tt.name, tt.expected, visited)
+		}
+	}
+}
+
+func TestHandleStepTimeoutDefault(t *testing.T) {
+	w, err := LoadWizard(writeWizard(t, `
+steps:
+  - id: details
+    type: form
+    timeout: 1m
+    on_timeout: default
+    form:
+      groups:
+        - fields:
+            - {type: input, key: name, value: anonymous}
+            - {type: confirm, key: subscribe, value: true}
+            - {type: input, key: email}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := handleStepTimeout(context.Background(), w.Steps[0], map[string]interface{}{"email": "a@b.c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"name": "anonymous", "subscribe": true, "email": "a@b.c"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
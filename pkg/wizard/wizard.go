@@ -2,9 +2,16 @@ package wizard
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/expr-lang/expr"
+	"github.com/go-go-golems/uhoh/pkg"
+	"github.com/go-go-golems/uhoh/pkg/wizard/shellcmd"
 	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -38,6 +45,7 @@ type Wizard struct {
 	Description string                 `yaml:"description,omitempty"`
 	Steps       steps.WizardSteps      `yaml:"steps"` // Custom type for unmarshalling
 	Theme       string                 `yaml:"theme,omitempty"`
+	Timeout     string                 `yaml:"timeout,omitempty"` // Bounds the whole run, e.g. "10m"
 	GlobalState map[string]interface{} `yaml:"global_state,omitempty"`
 
 	// Non-YAML fields
@@ -45,8 +53,18 @@ type Wizard struct {
 	callbacks       map[string]WizardCallbackFunc
 	actionCallbacks map[string]ActionCallbackFunc // New field for action-specific callbacks
 	initialState    map[string]interface{}        // Added for external initial state
+	sourceHash      string                        // Hash of the wizard file, recorded in checkpoints
+	checkpointFile  string                        // Where to save checkpoints, if set
+	resume          *Checkpoint                   // Checkpoint to resume from, if set
+	answers         map[string]interface{}        // Scripted answers by step ID, for headless runs
+	stepObserver    StepObserver                  // Told about every step before it runs, if set
+	stepMocks       map[string]StepMockFunc       // Run instead of the steps with these IDs
 }
 
+// StepObserver is called with the ID of every step the runner is about to
+// execute (after its skip condition) and the state the step starts from.
+type StepObserver func(stepID string, state map[string]interface{})
+
 // WizardOption is used to configure a Wizard during creation.
 type WizardOption func(*Wizard)
 
@@ -101,6 +119,25 @@ func WithInitialState(state map[string]interface{}) WizardOption {
 	}
 }
 
+// WithScriptedAnswers makes Run headless: instead of showing the steps, it
+// answers them from answers, which maps step IDs to the answers of the step
+// (see pkg.LoadAnswers). Form steps take their field values from it, decision
+// steps the value of their target_key, and action steps with an on_error
+// prompt their `on_error` choice.
+func WithScriptedAnswers(answers map[string]interface{}) WizardOption {
+	return func(w *Wizard) {
+		w.answers = answers
+	}
+}
+
+// WithStepObserver registers a function that is told about every step
+// before it runs, for example to record the path through the wizard.
+func WithStepObserver(observer StepObserver) WizardOption {
+	return func(w *Wizard) {
+		w.stepObserver = observer
+	}
+}
+
 // WithActionCallback registers a callback function specifically for action steps.
 func WithActionCallback(name string, fn ActionCallbackFunc) WizardOption {
 	return func(w *Wizard) {
@@ -163,15 +200,51 @@ func (w *Wizard) evaluateExprCondition(condition string, state map[string]interf
 	return boolResult, nil
 }
 
-// Run executes the wizard steps sequentially.
-// It now accepts an initial state map that overrides/merges with the global state.
+// Run executes the wizard steps sequentially in a single Bubble Tea program
+// (see model). It accepts an initial state map that overrides/merges with the
+// global state. With WithScriptedAnswers, the steps run without a terminal.
 func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (map[string]interface{}, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := w.validateStepMocks(); err != nil {
+		return nil, err
+	}
+	if w.answers != nil {
+		if err := w.validateAnswers(); err != nil {
+			return nil, err
+		}
+		return w.runSteps(ctx, initialState, nil)
+	}
+	m := w.newModel(ctx, initialState)
+	p := tea.NewProgram(m, tea.WithContext(ctx), tea.WithAltScreen(), tea.WithReportFocus())
+	if _, err := p.Run(); err != nil {
+		m.cancel()
+		return m.state, errors.Wrap(err, "error running wizard program")
+	}
+	return m.state, m.err
+}
+
+// runSteps is the step loop behind Run. Steps show their views in the host
+// carried by ctx (see pkg.WithHost). If listener is set, it is told about
+// every step before the step runs.
+func (w *Wizard) runSteps(ctx context.Context, initialState map[string]interface{}, listener stepListener) (map[string]interface{}, error) {
 	logger := log.With().Str("wizardName", w.Name).Logger()
 	logger.Debug().Msg("Starting Wizard")
 	if w.Description != "" {
 		logger.Debug().Msg(w.Description)
 	}
 
+	timeout, err := w.timeout()
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// --- State Management: Initialize state ---
 	wizardState := make(map[string]interface{})
 	// 1. Load GlobalState from YAML
@@ -216,7 +289,29 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 
 	// Basic execution loop - iterates through steps sequentially
 	currentStepIndex := 0
+	// Executed steps, most recent last, for going back
+	var history []historyEntry
+	if w.resume != nil {
+		var err error
+		history, currentStepIndex, wizardState, err = w.restoreCheckpoint(w.resume)
+		if err != nil {
+			return wizardState, err
+		}
+		logger.Debug().Str("currentStepId", w.resume.CurrentStepID).Int("historyLength", len(history)).Msg("Resuming from checkpoint")
+	}
+	// Previous answers of a step the user went back to
+	var revisit map[string]interface{}
+	// Steps that ran after a step the user edits from a summary, in order.
+	// Their results are kept instead of asking again as long as the run
+	// follows the same path; as soon as it leaves it (a step is skipped or
+	// branched to differently, or the user goes back), the rest is dropped
+	// and the steps are asked again.
+	var replay []historyEntry
 	for currentStepIndex < len(w.Steps) {
+		if err := w.checkWizardTimeout(ctx); err != nil {
+			return wizardState, err
+		}
+
 		step := w.Steps[currentStepIndex]
 		stepID := step.ID()
 		stepType := step.Type()
@@ -241,20 +336,49 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 				// Optionally return error: return wizardState, errors.Wrapf(err, "error evaluating skip condition for step %s", stepID)
 			} else if skip {
 				stepLogger.Debug().Str("condition", skipCond).Msg("Skipping step due to condition")
+				revisit = nil
+				if len(replay) > 0 && replay[0].index == currentStepIndex {
+					stepLogger.Debug().Msg("Step ran before the edit and is now skipped, asking the following steps again")
+					replay = nil
+				}
 				currentStepIndex++
 				continue
 			}
 		}
 		// --- Skip Condition Check --- END ---
 
+		if listener != nil {
+			listener(w.stepProgress(currentStepIndex, history, wizardState))
+		}
+		if w.stepObserver != nil {
+			w.stepObserver(stepID, wizardState)
+		}
+
+		stateBefore := maps.Clone(wizardState)
+		var previousResult map[string]interface{}
+		replaying := false
+		// The edited step itself (which runs with revisit set) is asked
+		// again; the replay starts with the step after it.
+		if len(replay) > 0 && revisit == nil {
+			if replay[0].index == currentStepIndex {
+				previousResult, replaying = replay[0].result, true
+				replay = replay[1:]
+			} else {
+				stepLogger.Debug().Msg("The edit changed the path, asking the following steps again")
+				replay = nil
+			}
+		}
+
 		// --- Before Callback --- START ---
-		if beforeCallbackName := step.BeforeCallback(); beforeCallbackName != "" {
+		if beforeCallbackName := step.BeforeCallback(); beforeCallbackName != "" && !replaying {
 			callback, found := w.callbacks[beforeCallbackName]
 			if !found {
 				stepLogger.Warn().Str("callbackName", beforeCallbackName).Msg("'before' callback not registered, skipping")
 			} else {
 				stepLogger.Debug().Str("callbackName", beforeCallbackName).Msg("Executing 'before' callback")
-				_, _, err := callback(ctx, wizardState)
+				callbackCtx, cancel := withStepTimeout(ctx, step)
+				_, _, err := callback(callbackCtx, wizardState)
+				cancel()
 				if err != nil {
 					return wizardState, errors.Wrapf(err, "'before' callback '%s' for step '%s' failed", beforeCallbackName, stepID)
 				}
@@ -266,9 +390,74 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 		stepLogger.Debug().Msgf("Executing Step %d/%d", currentStepIndex+1, len(w.Steps))
 
 		// --- State Management: Pass state to step --- // TODO(manuel, 2024-08-06) Pass logger too?
-		stepResult, err := step.Execute(ctx, wizardState)
+		var stepResult map[string]interface{}
+		var err error
+		if replaying {
+			stepLogger.Debug().Msg("Keeping the previous answers of the step after an edit")
+			stepResult = previousResult
+		} else {
+			stepCtx, cancel := withStepTimeout(steps.WithFieldSources(ctx, w.fieldSources(history)), step)
+			if w.answers != nil {
+				stepCtx = pkg.WithAnswers(stepCtx, w.stepAnswers(stepID))
+			}
+			stepResult, err = w.executeStep(stepCtx, step, withAnswers(wizardState, revisit))
+			if err != nil && stepTimedOut(ctx, stepCtx) {
+				stepLogger.Warn().Str("timeout", step.GetBaseStep().StepTimeout).Msg("Step timed out")
+				stepResult, err = handleStepTimeout(ctx, step, withAnswers(wizardState, revisit))
+			}
+			cancel()
+		}
 		// --- End State Management --- //
 
+		if errors.Is(err, steps.ErrGoBack) {
+			entry, rest, ok := w.popHistory(history)
+			if !ok {
+				stepLogger.Debug().Msg("No previous step to go back to, showing the step again")
+				wizardState = stateBefore
+				continue
+			}
+			history = rest
+			wizardState = entry.stateBefore
+			revisit = entry.result
+			replay = nil
+			currentStepIndex = entry.index
+			stepLogger.Debug().Str("previousStepId", w.Steps[entry.index].ID()).Msg("Going back to previous step")
+			continue
+		}
+
+		var editRequest *steps.EditRequest
+		if errors.As(err, &editRequest) {
+			pos := findSource(history, editRequest.Key)
+			if pos == -1 {
+				stepLogger.Warn().Str("key", editRequest.Key).Msg("No step set the key to edit, showing the step again")
+				wizardState = stateBefore
+				continue
+			}
+			entry := history[pos]
+			replay = append([]historyEntry{}, history[pos+1:]...)
+			// Re-run the owning step from the state it saw, pre-filled with
+			// the current values of the keys it set
+			revisit = map[string]interface{}{}
+			for k := range entry.result {
+				if v, ok := wizardState[k]; ok {
+					revisit[k] = v
+				}
+			}
+			history = history[:pos]
+			wizardState = entry.stateBefore
+			currentStepIndex = entry.index
+			stepLogger.Debug().Str("key", editRequest.Key).Str("editStepId", w.Steps[entry.index].ID()).Msg("Editing field")
+			continue
+		}
+
+		var jump *steps.JumpRequest
+		if errors.As(err, &jump) {
+			stepLogger.Warn().Err(jump.Err).Str("gotoStepId", jump.StepID).Msg("Step failed, continuing at its on_error step")
+			stepResult = jump.Result
+			*nextStepIDOverride = jump.StepID
+			err = nil
+		}
+
 		if err != nil {
 			// Check for specific errors like Abort or NotImplemented
 			if errors.Is(err, steps.ErrUserAborted) {
@@ -280,6 +469,9 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 				stepLogger.Warn().Msg("Step is not fully implemented. Skipping execution logic.")
 				stepResult = map[string]interface{}{} // Treat as empty result to continue loop
 			} else {
+				if timeoutErr := w.checkWizardTimeout(ctx); timeoutErr != nil {
+					return wizardState, timeoutErr
+				}
 				// For other errors, halt execution
 				stepLogger.Error().Err(err).Msg("Error executing step")
 				return wizardState, errors.Wrapf(err, "error executing step %d (ID: %s)", currentStepIndex, stepID)
@@ -287,13 +479,15 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 		}
 
 		// --- After Callback --- START ---
-		if afterCallbackName := step.AfterCallback(); afterCallbackName != "" {
+		if afterCallbackName := step.AfterCallback(); afterCallbackName != "" && !replaying {
 			callback, found := w.callbacks[afterCallbackName]
 			if !found {
 				stepLogger.Warn().Str("callbackName", afterCallbackName).Msg("'after' callback not registered, skipping")
 			} else {
 				stepLogger.Debug().Str("callbackName", afterCallbackName).Msg("Executing 'after' callback")
-				_, _, err := callback(ctx, wizardState)
+				callbackCtx, cancel := withStepTimeout(ctx, step)
+				_, _, err := callback(callbackCtx, wizardState)
+				cancel()
 				if err != nil {
 					return wizardState, errors.Wrapf(err, "'after' callback '%s' for step '%s' failed", afterCallbackName, stepID)
 				}
@@ -317,13 +511,15 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 		// --- End State Management ---
 
 		// --- Validation Callback --- START ---
-		if validationCallbackName := step.ValidationCallback(); validationCallbackName != "" {
+		if validationCallbackName := step.ValidationCallback(); validationCallbackName != "" && !replaying {
 			callback, found := w.callbacks[validationCallbackName]
 			if !found {
 				stepLogger.Warn().Str("callbackName", validationCallbackName).Msg("'validation' callback not registered, skipping")
 			} else {
 				stepLogger.Debug().Str("callbackName", validationCallbackName).Msg("Executing 'validation' callback")
-				_, _, err := callback(ctx, wizardState)
+				callbackCtx, cancel := withStepTimeout(ctx, step)
+				_, _, err := callback(callbackCtx, wizardState)
+				cancel()
 				if err != nil {
 					// Validation failure should likely halt the process or trigger remediation (TBD)
 					return wizardState, errors.Wrapf(err, "'validation' callback '%s' for step '%s' failed", validationCallbackName, stepID)
@@ -337,13 +533,16 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 		var nextStepIndex int
 
 		// --- Navigation Callback --- START ---
-		if navigationCallbackName := step.NavigationCallback(); navigationCallbackName != "" {
+		// An on_error jump takes precedence over the navigation callback
+		if navigationCallbackName := step.NavigationCallback(); navigationCallbackName != "" && *nextStepIDOverride == "" {
 			callback, found := w.callbacks[navigationCallbackName]
 			if !found {
 				stepLogger.Warn().Str("callbackName", navigationCallbackName).Msg("'navigation' callback not registered, using default navigation")
 			} else {
 				stepLogger.Debug().Str("callbackName", navigationCallbackName).Msg("Executing 'navigation' callback")
-				_, nextStepIDPtr, err := callback(ctx, wizardState)
+				callbackCtx, cancel := withStepTimeout(ctx, step)
+				_, nextStepIDPtr, err := callback(callbackCtx, wizardState)
+				cancel()
 				if err != nil {
 					return wizardState, errors.Wrapf(err, "'navigation' callback '%s' for step '%s' failed", navigationCallbackName, stepID)
 				}
@@ -358,14 +557,9 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 		// --- Navigation Callback --- END ---
 
 		// --- Navigation Logic --- START ---
+		declaredNextStep := w.declaredNextStep(step, wizardState)
 		if *nextStepIDOverride != "" {
-			foundIndex := -1
-			for i, s := range w.Steps {
-				if s.ID() == *nextStepIDOverride {
-					foundIndex = i
-					break
-				}
-			}
+			foundIndex := w.stepIndex(*nextStepIDOverride)
 			if foundIndex == -1 {
 				err := errors.Errorf("navigation callback requested jump to non-existent step ID: '%s' from step '%s'", *nextStepIDOverride, stepID)
 				stepLogger.Error().Err(err).Str("requestedStepId", *nextStepIDOverride).Msg("Invalid navigation target")
@@ -373,8 +567,14 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 			}
 			nextStepIndex = foundIndex
 			stepLogger.Debug().Int("nextStepIndex", nextStepIndex).Str("nextStepId", *nextStepIDOverride).Msg("Navigating based on callback override")
+		} else if declaredNextStep != "" {
+			// next_step / next_step_map targets are checked in LoadWizard
+			nextStepIndex = w.stepIndex(declaredNextStep)
+			if nextStepIndex == -1 {
+				return wizardState, errors.Errorf("step '%s' has next step '%s', which does not exist", stepID, declaredNextStep)
+			}
+			stepLogger.Debug().Int("nextStepIndex", nextStepIndex).Str("nextStepId", declaredNextStep).Msg("Navigating to declared next step")
 		} else {
-			// TODO(manuel, 2024-08-06) Add logic for next_step_map (decision) and next_step field here
 			// Default linear progression if no override or specific field
 			nextStepIndex = currentStepIndex + 1
 			if nextStepIndex < len(w.Steps) {
@@ -385,14 +585,91 @@ func (w *Wizard) Run(ctx context.Context, initialState map[string]interface{}) (
 		}
 		// --- Navigation Logic --- END ---
 
+		history = append(history, historyEntry{
+			index:       currentStepIndex,
+			stateBefore: stateBefore,
+			result:      stepResult,
+		})
+		revisit = nil
+		w.saveCheckpoint(history, nextStepIndex, wizardState)
+
 		currentStepIndex = nextStepIndex
 	}
 
 	logger.Debug().Interface("finalState", wizardState).Msg("Wizard Finished")
 
+	if w.checkpointFile != "" {
+		if err := os.Remove(w.checkpointFile); err != nil && !os.IsNotExist(err) {
+			logger.Warn().Err(err).Str("checkpointFile", w.checkpointFile).Msg("Could not remove checkpoint file")
+		}
+	}
+
 	return wizardState, nil
 }
 
+// declaredNextStep returns the next step declared in the YAML for step: the
+// next_step_map entry of a decision step for the chosen value, or next_step.
+// An empty result means the following step.
+func (w *Wizard) declaredNextStep(step steps.Step, state map[string]interface{}) string {
+	if ds, ok := step.(*steps.DecisionStep); ok {
+		choice, _ := state[ds.TargetKey].(string)
+		return ds.NextStepFor(choice)
+	}
+	return step.GetBaseStep().NextStep
+}
+
+// stepIndex returns the index of the step with the given ID, len(w.Steps) for
+// the `end` target, or -1 if there is no such step.
+func (w *Wizard) stepIndex(id string) int {
+	if id == steps.EndStepID {
+		return len(w.Steps)
+	}
+	for i, s := range w.Steps {
+		if s.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateNavigation checks that every next_step, next_step_map and on_error
+// goto target names an existing step or `end`, and that next_step_map keys are
+// choices of their decision step (or `default`).
+func (w *Wizard) validateNavigation() error {
+	checkTarget := func(stepID string, field string, target string) error {
+		if target == "" || w.stepIndex(target) != -1 {
+			return nil
+		}
+		return errors.Errorf("step '%s' has %s '%s', which is not a step ID or '%s'", stepID, field, target, steps.EndStepID)
+	}
+
+	for _, step := range w.Steps {
+		if err := checkTarget(step.ID(), "next_step", step.GetBaseStep().NextStep); err != nil {
+			return err
+		}
+		if as, ok := step.(*steps.ActionStep); ok && as.OnError != nil {
+			if err := checkTarget(as.ID(), "on_error goto", as.OnError.Goto); err != nil {
+				return err
+			}
+		}
+
+		ds, ok := step.(*steps.DecisionStep)
+		if !ok {
+			continue
+		}
+		for choice, target := range ds.NextStepMap {
+			if choice != steps.NextStepMapDefault && len(ds.Choices) > 0 && !slices.Contains(ds.Choices, choice) {
+				return errors.Errorf("step '%s' has a next_step_map entry for '%s', which is not one of its choices", ds.ID(), choice)
+			}
+			if err := checkTarget(ds.ID(), fmt.Sprintf("next_step_map target for '%s'", choice), target); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // LoadWizard loads a Wizard definition from a YAML file and applies options.
 func LoadWizard(filePath string, opts ...WizardOption) (*Wizard, error) {
 	yamlData, err := os.ReadFile(filePath)
@@ -415,6 +692,8 @@ func LoadWizard(filePath string, opts ...WizardOption) (*Wizard, error) {
 		return nil, errors.Wrap(err, "could not unmarshal wizard YAML (check structure/types, possibly caught by custom step unmarshaler)")
 	}
 
+	wizard.sourceHash = hashWizardSource(yamlData)
+
 	// Apply functional options *after* unmarshalling
 	for _, opt := range opts {
 		opt(&wizard)
@@ -432,16 +711,37 @@ func LoadWizard(filePath string, opts ...WizardOption) (*Wizard, error) {
 			// Should be caught by the custom unmarshaller
 			return nil, errors.Errorf("step %d (type: %s) is missing required 'id' field", i, step.Type())
 		}
+		if stepID == steps.EndStepID {
+			return nil, errors.Errorf("step %d uses the reserved ID '%s'", i, steps.EndStepID)
+		}
 		if _, exists := stepIDs[stepID]; exists {
 			return nil, errors.Errorf("duplicate step ID found: %s", stepID)
 		}
 		stepIDs[stepID] = true
 
+		// options_from files are relative to the wizard file
+		if fs, ok := step.(*steps.FormStep); ok {
+			fs.FormData.Dir = filepath.Dir(filePath)
+		}
+
+		if v, ok := step.(steps.Validator); ok {
+			if err := v.Validate(); err != nil {
+				return nil, err
+			}
+		}
+
 		// Remove the type switch validation here; it's handled by the custom unmarshaller
 		// and caused linter errors due to signature mismatches during refactoring.
 		// The custom unmarshaller provides more specific error messages if decoding fails.
 	}
 
+	if err := wizard.validateNavigation(); err != nil {
+		return nil, err
+	}
+	if _, err := wizard.timeout(); err != nil {
+		return nil, err
+	}
+
 	log.Debug().Str("filePath", filePath).Str("wizardName", wizard.Name).Int("stepCount", len(wizard.Steps)).Msg("Wizard loaded successfully")
 	return &wizard, nil
 }
@@ -461,3 +761,21 @@ func (w *Wizard) ExecuteActionCallback(ctx context.Context, callbackName string,
 
 	return callback(ctx, state, args)
 }
+
+// Make sure Wizard can run shell actions
+var _ steps.ShellActionRunner = &Wizard{}
+
+// RunShellAction runs the command of an `action_type: shell` step in the
+// shellcmd viewer and returns the shellcmd.Result as a map for the state.
+func (w *Wizard) RunShellAction(ctx context.Context, command string, opts steps.ShellActionOptions) (map[string]interface{}, error) {
+	res, err := shellcmd.Run(ctx, command, shellcmd.Options{
+		WorkDir:  opts.WorkDir,
+		Env:      opts.Env,
+		Title:    opts.Title,
+		KeepOpen: opts.KeepOpen,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run shell viewer")
+	}
+	return res.Map(), res.Err
+}
@@ -0,0 +1,189 @@
+package wizard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/uhoh/pkg/wizard/steps"
+)
+
+// writeWizard writes a wizard file into a temporary directory and returns its
+// path.
+func writeWizard(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wizard.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadWizardErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		wizard  string
+		problem string
+	}{
+		{
+			name: "mixed option types",
+			wizard: `
+name: Mixed
+steps:
+  - id: pick
+    type: form
+    form:
+      groups:
+        - fields:
+            - type: select
+              key: choice
+              options:
+                - label: One
+                  value: 1
+                - label: Two
+                  value: "two"
+`,
+			problem: "all option values of a field must have the same type",
+		},
+		{
+			name: "duplicate step ID",
+			wizard: `
+steps:
+  - id: a
+    type: info
+    content: A
+  - id: a
+    type: info
+    content: B
+`,
+			problem: "duplicate step ID",
+		},
+		{
+			name: "reserved step ID",
+			wizard: `
+steps:
+  - id: end
+    type: info
+    content: Bye
+`,
+			problem: "uses the reserved ID",
+		},
+		{
+			name: "unknown next_step",
+			wizard: `
+steps:
+  - id: a
+    type: info
+    content: A
+    next_step: nowhere
+`,
+			problem: "which is not a step ID or 'end'",
+		},
+		{
+			name: "unknown next_step_map target",
+			wizard: `
+steps:
+  - id: pick
+    type: decision
+    target_key: choice
+    choices: ["yes", "no"]
+    next_step_map:
+      "yes": nowhere
+`,
+			problem: "which is not a step ID or 'end'",
+		},
+		{
+			name: "next_step_map key that is not a choice",
+			wizard: `
+steps:
+  - id: pick
+    type: decision
+    target_key: choice
+    choices: ["yes", "no"]
+    next_step_map:
+      maybe: end
+`,
+			problem: "is not one of its choices",
+		},
+		{
+			name: "unknown on_error goto",
+			wizard: `
+steps:
+  - id: fetch
+    type: action
+    action_type: function
+    function_name: fetch
+    on_error:
+      then: goto
+      goto: nowhere
+`,
+			problem: "has on_error goto 'nowhere'",
+		},
+		{
+			name: "invalid step timeout",
+			wizard: `
+steps:
+  - {id: a, type: info, content: A, timeout: soon}
+`,
+			problem: "invalid timeout in step a",
+		},
+		{
+			name: "default on a step without answers",
+			wizard: `
+steps:
+  - {id: a, type: info, content: A, timeout: 1m, on_timeout: default}
+`,
+			problem: "on_timeout default is only supported by form steps",
+		},
+		{
+			name: "unknown on_timeout",
+			wizard: `
+steps:
+  - {id: a, type: info, content: A, timeout: 1m, on_timeout: wait}
+`,
+			problem: "unknown on_timeout 'wait'",
+		},
+		{
+			name: "invalid wizard timeout",
+			wizard: `
+timeout: -5m
+steps:
+  - {id: a, type: info, content: A}
+`,
+			problem: "wizard timeout must be positive",
+		},
+	}
+	for _, tt := range tests {
+		_, err := LoadWizard(writeWizard(t, tt.wizard))
+		if err == nil || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+}
+
+func TestLoadWizardSetsFormDir(t *testing.T) {
+	path := writeWizard(t, `
+steps:
+  - id: pick
+    type: form
+    form:
+      groups:
+        - fields:
+            - type: select
+              key: color
+              options_from:
+                file: colors.yaml
+`)
+	w, err := LoadWizard(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, ok := w.Steps[0].(*steps.FormStep)
+	if !ok {
+		t.Fatalf("expected a form step, got %T", w.Steps[0])
+	}
+	if fs.FormData.Dir != filepath.Dir(path) {
+		t.Errorf("expected options_from files to be resolved in %s, got %q", filepath.Dir(path), fs.FormData.Dir)
+	}
+}